@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// UI and HUD scales offered in the accessibility screen, in percent
+var uiScales = []int{100, 125, 150, 200}
+
+// ==================== ACCESSIBILITY ====================
+
+// Accessibility holds the per-profile comfort settings. The zero value is
+// the game's normal look.
+type Accessibility struct {
+	Shapes        bool `json:"shapes"`         // draw a glyph on every item so colour is not needed
+	UIScale       int  `json:"ui_scale"`       // menus and overlays, percent (0 means 100)
+	HUDScale      int  `json:"hud_scale"`      // in-game HUD, percent (0 means 100)
+	NoShake       bool `json:"no_shake"`       // no screen shake
+	NoFlash       bool `json:"no_flash"`       // steady colours instead of flashing ones
+	ReducedMotion bool `json:"reduced_motion"` // freeze meteors, stars and the grid shimmer
+}
+
+// access is the active profile's accessibility settings. Headless games,
+// such as replays being verified, have no profile and get the defaults.
+func (g *Game) access() *Accessibility {
+	if g.profile == nil {
+		return &Accessibility{}
+	}
+	return &g.profile.Access
+}
+
+func scalePercent(p int) float64 {
+	if p <= 0 {
+		return 1
+	}
+	return float64(p) / 100
+}
+
+func nextScale(current, delta int) int {
+	if current <= 0 {
+		current = 100
+	}
+	index := 0
+	for i, s := range uiScales {
+		if s == current {
+			index = i
+		}
+	}
+	return uiScales[(index+delta+len(uiScales))%len(uiScales)]
+}
+
+// shake starts a screen shake unless the player turned it off.
+func (g *Game) shake(intensity float64) {
+	if !g.access().NoShake {
+		g.shakeIntensity = intensity
+	}
+}
+
+// flashOn reports whether a flashing effect is in its bright phase. With
+// flashing disabled the effect is held steady in that phase.
+func (g *Game) flashOn(phase int) bool {
+	return g.access().NoFlash || phase%2 == 0
+}
+
+// ==================== ITEM GLYPHS ====================
+
+// Item kinds for glyphs; power-ups use their type index
+const (
+	glyphBonus = iota
+	glyphSpeed
+	glyphShield
+	glyphFood
+	glyphHazard
+)
+
+// drawItemGlyph marks an item with a shape so it can be told apart without
+// relying on colour: a dot for food, a star for bonus, a double chevron for
+// speed, a square for the shield and a cross for asteroids.
+func (g *Game) drawItemGlyph(screen *ebiten.Image, p Point, kind int) {
+	if !g.access().Shapes {
+		return
+	}
+
+	cell := float32(g.cellSize)
+	x, y := g.cellCenter(p)
+	cx, cy := float32(x), float32(y)
+	r := cell * 0.28
+	w := cell * 0.12
+	if w < 1.5 {
+		w = 1.5
+	}
+	c := color.RGBA{palette.Background.R, palette.Background.G, palette.Background.B, 230}
+
+	switch kind {
+	case glyphFood:
+		vector.DrawFilledCircle(screen, cx, cy, r*0.7, c, true)
+	case glyphBonus:
+		vector.StrokeLine(screen, cx-r, cy, cx+r, cy, w, c, true)
+		vector.StrokeLine(screen, cx, cy-r, cx, cy+r, w, c, true)
+		vector.StrokeLine(screen, cx-r*0.7, cy-r*0.7, cx+r*0.7, cy+r*0.7, w, c, true)
+		vector.StrokeLine(screen, cx-r*0.7, cy+r*0.7, cx+r*0.7, cy-r*0.7, w, c, true)
+	case glyphSpeed:
+		for _, dx := range []float32{-r * 0.5, r * 0.4} {
+			vector.StrokeLine(screen, cx+dx-r*0.4, cy-r, cx+dx+r*0.4, cy, w, c, true)
+			vector.StrokeLine(screen, cx+dx+r*0.4, cy, cx+dx-r*0.4, cy+r, w, c, true)
+		}
+	case glyphShield:
+		vector.StrokeRect(screen, cx-r, cy-r, 2*r, 2*r, w, c, true)
+	case glyphHazard:
+		vector.StrokeLine(screen, cx-r, cy-r, cx+r, cy+r, w, c, true)
+		vector.StrokeLine(screen, cx-r, cy+r, cx+r, cy-r, w, c, true)
+	}
+}
+
+// ==================== ACCESSIBILITY SCREEN ====================
+
+func (g *Game) accessOptions() []Widget {
+	a := g.access()
+	scale := func(p int) string { return fmt.Sprintf("%d%%", int(scalePercent(p)*100)) }
+	return []Widget{
+		&Toggle{Label: tr("access.shapes"), On: a.Shapes, OnChange: func() { a.Shapes = !a.Shapes }},
+		&Choice{Label: tr("access.menu_scale"), Value: scale(a.UIScale), OnChange: func(d int) { a.UIScale = nextScale(a.UIScale, d) }},
+		&Choice{Label: tr("access.hud_scale"), Value: scale(a.HUDScale), OnChange: func(d int) { a.HUDScale = nextScale(a.HUDScale, d) }},
+		&Toggle{Label: tr("access.shake"), On: !a.NoShake, OnChange: func() { a.NoShake = !a.NoShake }},
+		&Toggle{Label: tr("access.flashing"), On: !a.NoFlash, OnChange: func() { a.NoFlash = !a.NoFlash }},
+		&Toggle{Label: tr("access.reduced_motion"), On: a.ReducedMotion, OnChange: func() { a.ReducedMotion = !a.ReducedMotion }},
+	}
+}
+
+func (g *Game) openAccessibility() {
+	g.accessCursor = 0
+	g.pushScene(&screenScene{update: g.updateAccessibility, draw: g.drawAccessibilityScreen})
+}
+
+func (g *Game) accessibilityView() Widget {
+	list := &List{Items: g.accessOptions(), Cursor: &g.accessCursor, RowHeight: 30, OnChange: func() {
+		if g.access().NoShake {
+			g.shakeIntensity = 0
+		}
+		g.saveGameData()
+	}}
+	return g.optionScreen(tr("access.title"), list, tr("options.hint"))
+}
+
+func (g *Game) updateAccessibility() error {
+	g.updateUI(g.accessibilityView())
+	return nil
+}
+
+func (g *Game) drawAccessibilityScreen(screen *ebiten.Image) {
+	g.renderUI(screen, g.accessibilityView())
+}
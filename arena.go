@@ -0,0 +1,215 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	// Smallest cell, in pixels at the reference window size, before the
+	// arena stops fitting the window and the camera scrolls it instead
+	scrollCellSize = 16
+
+	cameraLookAhead = 4.0  // cells ahead of the head the camera aims at
+	cameraSmoothing = 0.08 // fraction of the distance covered each frame
+
+	minimapSize = 160.0 // longest side, in HUD pixels
+
+	// Limits for arenas sized to the window
+	autoCellSize = 15
+	autoMaxW     = 50
+	autoMaxH     = 40
+	autoMinW     = 20
+	autoMinH     = 15
+)
+
+// ==================== ARENA VIEW ====================
+
+// The arena's size in cells is chosen when a run starts and never changes
+// during it. Resizing the window or toggling fullscreen only rescales the
+// view: the cell size follows the window and the arena is centred, with the
+// space background filling the margins. Arenas too big to fit at
+// scrollCellSize are drawn at that size and a camera follows the head.
+
+// chooseArena sets the grid size for a new run: the fixed size from the
+// settings or challenge, or one that fits the current window. A run started
+// before the first Layout gets the smallest auto arena for now, and Layout
+// chooses again once the window is known.
+func (g *Game) chooseArena() {
+	g.awaitingWindow = false
+	if g.fixedArena.X > 0 && g.fixedArena.Y > 0 {
+		g.gridW, g.gridH = g.fixedArena.X, g.fixedArena.Y
+	} else if g.screenWidth <= 0 || g.screenHeight <= 0 {
+		g.gridW, g.gridH = autoMinW, autoMinH
+		g.awaitingWindow = true
+	} else {
+		g.gridW, g.gridH = autoArenaSize(g.screenWidth, g.screenHeight)
+	}
+	g.fitArena()
+}
+
+// autoArenaSize picks a grid for a w by h pixel window, keeping its aspect
+// ratio and cells of at least autoCellSize pixels.
+func autoArenaSize(w, h int) (int, int) {
+	aspectRatio := float64(w) / float64(h)
+
+	var gridW, gridH int
+	if aspectRatio > 1.5 { // Wide screen
+		gridW = int(math.Min(float64(w/autoCellSize), autoMaxW))
+		gridH = int(float64(gridW) / aspectRatio)
+	} else { // Standard or tall screen
+		gridH = int(math.Min(float64(h/autoCellSize), autoMaxH))
+		gridW = int(float64(gridH) * aspectRatio)
+	}
+
+	// Ensure minimum playfield size
+	if gridW < autoMinW {
+		gridW = autoMinW
+	}
+	if gridH < autoMinH {
+		gridH = autoMinH
+	}
+	return gridW, gridH
+}
+
+// fitArena scales the current arena to the window. It is safe to call every
+// frame and before any run has started.
+func (g *Game) fitArena() {
+	if g.gridW == 0 || g.gridH == 0 {
+		return
+	}
+	g.cellSize = int(math.Min(float64(g.screenWidth/g.gridW), float64(g.screenHeight/g.gridH)))
+	if minCell := int(scrollCellSize * g.viewScale()); g.cellSize < minCell {
+		g.cellSize = minCell
+	}
+	g.scaleFactor = float64(g.cellSize) / float64(baseCellSize)
+}
+
+// scrolls reports whether the arena is larger than the window.
+func (g *Game) scrolls() bool {
+	return g.gridW*g.cellSize > g.screenWidth || g.gridH*g.cellSize > g.screenHeight
+}
+
+// arenaOrigin is the on-screen top-left corner of the arena. Along an axis
+// that fits the window the arena is centred; along one that does not, the
+// camera decides.
+func (g *Game) arenaOrigin() (float64, float64) {
+	cell := float64(g.cellSize)
+	return math.Floor(viewOrigin(g.screenWidth, g.gridW, g.camera.X, cell)),
+		math.Floor(viewOrigin(g.screenHeight, g.gridH, g.camera.Y, cell))
+}
+
+// viewOrigin places an arena of cells cells along a screen of size pixels,
+// centring on camera while keeping the view inside the arena.
+func viewOrigin(size, cells int, camera, cell float64) float64 {
+	span := float64(cells) * cell
+	if span <= float64(size) {
+		return (float64(size) - span) / 2
+	}
+	origin := float64(size)/2 - (camera+0.5)*cell
+	return math.Max(float64(size)-span, math.Min(0, origin))
+}
+
+// visibleCells is the range of columns and rows at least partly on screen.
+func (g *Game) visibleCells() (x0, y0, x1, y1 int) {
+	ox, oy := g.arenaOrigin()
+	cell := float64(g.cellSize)
+	x0 = int(math.Max(0, math.Floor(-ox/cell)))
+	y0 = int(math.Max(0, math.Floor(-oy/cell)))
+	x1 = int(math.Min(float64(g.gridW), math.Ceil((float64(g.screenWidth)-ox)/cell)))
+	y1 = int(math.Min(float64(g.gridH), math.Ceil((float64(g.screenHeight)-oy)/cell)))
+	return x0, y0, x1, y1
+}
+
+// cellCenter is the on-screen centre of cell p.
+func (g *Game) cellCenter(p Point) (float64, float64) {
+	x, y := g.arenaOrigin()
+	cell := float64(g.cellSize)
+	return x + (float64(p.X)+0.5)*cell, y + (float64(p.Y)+0.5)*cell
+}
+
+// ==================== CAMERA ====================
+
+// cameraTarget is the cell the camera aims at: a little ahead of the head so
+// the snake sees where it is going.
+func (g *Game) cameraTarget() Vector2 {
+	head := g.segmentPosition(0, g.moveProgress())
+	return Vector2{head.X + float64(g.dir.X)*cameraLookAhead, head.Y + float64(g.dir.Y)*cameraLookAhead}
+}
+
+// resetCamera puts the camera straight on its target, for a new run.
+func (g *Game) resetCamera() {
+	g.camera = g.cameraTarget()
+}
+
+// updateCamera eases the camera towards its target. When the head wraps
+// around an edge the camera cuts to the other side instead of panning across
+// the whole arena.
+func (g *Game) updateCamera() {
+	target := g.cameraTarget()
+	d := Vector2{target.X - g.camera.X, target.Y - g.camera.Y}
+	if math.Abs(d.X) > float64(g.gridW)/2 || math.Abs(d.Y) > float64(g.gridH)/2 {
+		g.camera = target
+		return
+	}
+	g.camera.X += d.X * cameraSmoothing
+	g.camera.Y += d.Y * cameraSmoothing
+}
+
+// ==================== MINIMAP ====================
+
+// drawMinimap shows the whole arena in the top-right corner when it does not
+// fit the window: the snake, food, power-up, asteroids and the part of the
+// arena on screen.
+func (g *Game) drawMinimap(screen *ebiten.Image) {
+	if !g.scrolls() {
+		return
+	}
+
+	scale := g.viewScale() * scalePercent(g.access().HUDScale)
+	k := minimapSize * scale / math.Max(float64(g.gridW), float64(g.gridH)) // pixels per cell
+	w, h := float32(float64(g.gridW)*k), float32(float64(g.gridH)*k)
+	margin := float32(15 * scale)
+	x := float32(g.screenWidth) - w - margin
+	y := margin
+
+	vector.DrawFilledRect(screen, x, y, w, h, palette.UI.HUDBackground, false)
+	vector.StrokeRect(screen, x, y, w, h, 1, palette.Grid, false)
+
+	// Items are at least a couple of pixels so they stay visible on huge arenas
+	dot := float32(math.Max(k, 2*scale))
+	mark := func(p Point, c color.Color) {
+		cx := x + (float32(p.X)+0.5)*float32(k)
+		cy := y + (float32(p.Y)+0.5)*float32(k)
+		vector.DrawFilledRect(screen, cx-dot/2, cy-dot/2, dot, dot, c, false)
+	}
+
+	for _, p := range g.hazards {
+		mark(p, palette.Hazard)
+	}
+	if g.powerUp.active {
+		mark(g.powerUp.pos, palette.PowerUps[g.powerUp.type_])
+	}
+	if g.foodActive {
+		mark(g.food, palette.Food)
+	}
+	for i := len(g.snake) - 1; i >= 0; i-- {
+		c := palette.Body
+		if i == 0 {
+			c = palette.Head
+		}
+		mark(g.snake[i], c)
+	}
+
+	// The part of the arena currently on screen
+	ox, oy := g.arenaOrigin()
+	cell := float64(g.cellSize)
+	vx := x + float32(math.Max(0, -ox/cell)*k)
+	vy := y + float32(math.Max(0, -oy/cell)*k)
+	vw := float32(math.Min(float64(g.screenWidth), float64(g.gridW)*cell) / cell * k)
+	vh := float32(math.Min(float64(g.screenHeight), float64(g.gridH)*cell) / cell * k)
+	vector.StrokeRect(screen, vx, vy, vw, vh, float32(math.Max(1, scale)), palette.UI.Text, false)
+}
@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+// A run started before ebiten's first Layout call still gets an arena that
+// fits the window.
+func TestEarlyRunGetsWindowSizedArena(t *testing.T) {
+	g := &Game{mode: modeEndless, rules: classicRules}
+	g.chooseArena()
+	g.startRun(1)
+
+	g.Layout(1280, 720)
+
+	wantW, wantH := autoArenaSize(1280, 720)
+	if g.gridW != wantW || g.gridH != wantH {
+		t.Fatalf("early arena is %dx%d, want %dx%d", g.gridW, g.gridH, wantW, wantH)
+	}
+	if head := g.snake[0]; head != (Point{wantW / 2, wantH / 2}) {
+		t.Errorf("snake starts at %v, want the centre of the new arena", head)
+	}
+
+	// Once sized, later resizes only rescale the view
+	g.Layout(800, 600)
+	if g.gridW != wantW || g.gridH != wantH {
+		t.Errorf("resize changed the arena to %dx%d", g.gridW, g.gridH)
+	}
+}
+
+func TestEarlyArenaKeepsItsSizeOnceMoving(t *testing.T) {
+	g := &Game{mode: modeEndless, rules: classicRules}
+	g.chooseArena()
+	g.startRun(1)
+	g.step()
+
+	g.Layout(1280, 720)
+
+	if g.gridW != autoMinW || g.gridH != autoMinH {
+		t.Errorf("arena changed to %dx%d mid-run", g.gridW, g.gridH)
+	}
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"embed"
+	"io/fs"
+	"os"
+)
+
+// ==================== BUNDLED ASSETS ====================
+
+// The assets that ship with the game are built into the binary, so they are
+// there whatever directory it is started from.
+//
+//go:embed assets/skins assets/themes assets/locales
+var bundledAssets embed.FS
+
+// assetSources are searched in order for skins, themes and locales: the
+// bundled ones first, then the working directory for files the player added.
+// A name found in an earlier source hides the same name in later ones.
+func assetSources() []fs.FS {
+	return []fs.FS{bundledAssets, os.DirFS(".")}
+}
@@ -0,0 +1,377 @@
+package main
+
+import (
+	"hash/fnv"
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	starTileSize   = 1024.0 // star layers repeat every this many pixels, so any window is filled
+	nebulaTexSize  = 256    // nebula texture, tiled and scaled up
+	nebulaScale    = 4.0
+	parallaxSpeed  = 40.0 // pixels per second of the nearest layer while the snake moves
+	parallaxEasing = 2.0  // how quickly the drift follows the snake, per second
+	variantFade    = 2.0  // seconds to cross-fade to a new background
+)
+
+// idleDrift is the parallax velocity when no run is moving
+var idleDrift = Vector2{6, 3}
+
+// ==================== PROCEDURAL BACKGROUND ====================
+
+// The space background is generated from a key made of the run's seed, the
+// current level and the theme, so every run and level gets its own sky and
+// the same seed always gives the same one. Star layers, the nebula and the
+// planets move at different speeds against the snake's heading to give a
+// sense of depth.
+
+type backgroundKind int
+
+const (
+	bgClusters  backgroundKind = iota // bright star clusters
+	bgNebula                          // a dense nebula
+	bgPlanetary                       // a few planets, some ringed
+	bgVoid                            // sparse stars and one distant giant
+	bgKindCount
+)
+
+// starLayer is a tile of stars that scrolls at depth times the parallax.
+type starLayer struct {
+	depth float64
+	stars []Star
+}
+
+// planet is a pre-rendered planet placed at a fraction of the screen.
+type planet struct {
+	x, y  float64
+	depth float64
+	img   *ebiten.Image
+}
+
+type backgroundVariant struct {
+	key     int64
+	layers  []starLayer
+	nebula  *ebiten.Image // nil when the variant has none
+	planets []planet
+}
+
+// backgroundKey identifies the variant for the current run, level and theme.
+func (r *Renderer) backgroundKey() int64 {
+	h := fnv.New64a()
+	h.Write([]byte(palette.ID))
+	return int64(h.Sum64()) ^ r.game.seed*1000003 ^ int64(r.game.level)*7919
+}
+
+// updateBackground switches to a new variant when the key changes and
+// eases the parallax drift towards the snake's heading.
+func (r *Renderer) updateBackground(dt float64, still bool) {
+	if key := r.backgroundKey(); r.bg == nil || r.bg.key != key {
+		r.prevBg = r.bg
+		r.bg = newBackgroundVariant(key)
+		r.bgFade = 0
+	}
+	if r.prevBg == nil || still {
+		r.prevBg = nil
+		r.bgFade = 1
+	} else if r.bgFade += dt / variantFade; r.bgFade >= 1 {
+		r.prevBg = nil
+		r.bgFade = 1
+	}
+	if still {
+		return
+	}
+
+	// Moving the far layers against the heading makes the snake feel like it travels
+	target := idleDrift
+	if g := r.game; g.playing() && !g.over {
+		target = Vector2{float64(g.dir.X) * parallaxSpeed, float64(g.dir.Y) * parallaxSpeed}
+	}
+	ease := math.Min(1, dt*parallaxEasing)
+	r.parallaxVel.X += (target.X - r.parallaxVel.X) * ease
+	r.parallaxVel.Y += (target.Y - r.parallaxVel.Y) * ease
+	r.parallax.X += r.parallaxVel.X * dt
+	r.parallax.Y += r.parallaxVel.Y * dt
+
+	for _, layer := range r.bg.layers {
+		for i := range layer.stars {
+			star := &layer.stars[i]
+			star.twinkle += star.speed * 6 * dt
+		}
+	}
+}
+
+// drawBackdrop draws the nebula, stars and planets, cross-fading from the
+// previous variant after a change.
+func (r *Renderer) drawBackdrop(screen *ebiten.Image) {
+	if r.prevBg != nil {
+		r.drawVariant(screen, r.prevBg, 1-r.bgFade)
+	}
+	if r.bg != nil {
+		r.drawVariant(screen, r.bg, r.bgFade)
+	}
+}
+
+func (r *Renderer) drawVariant(screen *ebiten.Image, v *backgroundVariant, alpha float64) {
+	scale := r.game.viewScale()
+
+	// Nebula, the farthest and softest layer; skipped at low effects quality
+	if v.nebula != nil && r.game.playerSettings().Effects != effectsLow {
+		tile := nebulaTexSize * nebulaScale * scale
+		ox := wrapRange(r.parallax.X*0.05, tile)
+		oy := wrapRange(r.parallax.Y*0.05, tile)
+		for x := -ox; x < float64(r.game.screenWidth); x += tile {
+			for y := -oy; y < float64(r.game.screenHeight); y += tile {
+				op := &ebiten.DrawImageOptions{Filter: ebiten.FilterLinear}
+				op.GeoM.Scale(nebulaScale*scale, nebulaScale*scale)
+				op.GeoM.Translate(x, y)
+				op.ColorScale.ScaleAlpha(float32(alpha))
+				screen.DrawImage(v.nebula, op)
+			}
+		}
+	}
+
+	// Far stars, then planets, then the nearer stars in front of them
+	for i, layer := range v.layers {
+		if i == 1 {
+			r.drawPlanets(screen, v, alpha)
+		}
+		r.drawStarLayer(screen, layer, alpha)
+	}
+	r.batch.flush(screen)
+}
+
+func (r *Renderer) drawStarLayer(screen *ebiten.Image, layer starLayer, alpha float64) {
+	w, h := float64(r.game.screenWidth), float64(r.game.screenHeight)
+	ox, oy := r.parallax.X*layer.depth, r.parallax.Y*layer.depth
+
+	visible := int(float64(len(layer.stars)) * r.game.effectsScale())
+	for i, star := range layer.stars[:visible] {
+		twinkleFactor := 0.7 + 0.3*math.Sin(star.twinkle)
+
+		// Calculate final brightness and size
+		finalBrightness := star.brightness * twinkleFactor * alpha
+		finalSize := star.size * (0.8 + 0.4*twinkleFactor)
+
+		// Star colours come from the theme
+		starColor := palette.Stars[i%len(palette.Stars)]
+		finalColor := color.RGBA{starColor.R, starColor.G, starColor.B, uint8(float64(starColor.A) * finalBrightness)}
+
+		// Repeat the tile across the whole window
+		for x := wrapRange(star.pos.X-ox, starTileSize); x < w; x += starTileSize {
+			for y := wrapRange(star.pos.Y-oy, starTileSize); y < h; y += starTileSize {
+				r.batch.rect(screen, x-finalSize/2, y-finalSize/2, finalSize, finalSize, finalColor)
+			}
+		}
+	}
+}
+
+func (r *Renderer) drawPlanets(screen *ebiten.Image, v *backgroundVariant, alpha float64) {
+	// Stars drawn so far go under the planets
+	r.batch.flush(screen)
+
+	w, h := float64(r.game.screenWidth), float64(r.game.screenHeight)
+	scale := r.game.viewScale()
+	for _, p := range v.planets {
+		pw := float64(p.img.Bounds().Dx()) * scale
+		ph := float64(p.img.Bounds().Dy()) * scale
+
+		// Planets drift out of one side and back in on the other
+		x := wrapRange(p.x*w-r.parallax.X*p.depth+pw, w+2*pw) - pw
+		y := wrapRange(p.y*h-r.parallax.Y*p.depth+ph, h+2*ph) - ph
+
+		op := &ebiten.DrawImageOptions{Filter: ebiten.FilterLinear}
+		op.GeoM.Scale(scale, scale)
+		op.GeoM.Translate(x-pw/2, y-ph/2)
+		op.ColorScale.ScaleAlpha(float32(alpha))
+		screen.DrawImage(p.img, op)
+	}
+}
+
+// wrapRange maps v into 0..size.
+func wrapRange(v, size float64) float64 {
+	v = math.Mod(v, size)
+	if v < 0 {
+		v += size
+	}
+	return v
+}
+
+// ==================== VARIANT GENERATION ====================
+
+func newBackgroundVariant(key int64) *backgroundVariant {
+	rng := rand.New(rand.NewSource(key))
+	kind := backgroundKind(rng.Intn(int(bgKindCount)))
+	v := &backgroundVariant{key: key}
+
+	// Three star layers from far to near
+	density := 1.0
+	switch kind {
+	case bgClusters:
+		density = 1.4
+	case bgVoid:
+		density = 0.5
+	}
+	for _, l := range []struct {
+		depth      float64
+		count      int
+		size       [2]float64
+		brightness [2]float64
+	}{
+		{0.15, 90, [2]float64{0.6, 1.2}, [2]float64{0.3, 0.6}},
+		{0.4, 50, [2]float64{1, 2}, [2]float64{0.4, 0.8}},
+		{1, 20, [2]float64{1.5, 3}, [2]float64{0.6, 1}},
+	} {
+		v.layers = append(v.layers, newStarLayer(rng, l.depth, int(float64(l.count)*density), l.size, l.brightness, kind == bgClusters))
+	}
+
+	switch kind {
+	case bgNebula:
+		v.nebula = newNebula(rng, 4, 40)
+		v.planets = newPlanets(rng, rng.Intn(2), 20, 45)
+	case bgPlanetary:
+		v.nebula = newNebula(rng, 2, 12)
+		v.planets = newPlanets(rng, 1+rng.Intn(3), 15, 60)
+	case bgVoid:
+		v.planets = newPlanets(rng, 1, 70, 110)
+	default:
+		v.nebula = newNebula(rng, 2, 20)
+	}
+	return v
+}
+
+// newStarLayer scatters count stars over one tile. Clustered layers gather
+// a share of them around a few centres.
+func newStarLayer(rng *rand.Rand, depth float64, count int, size, brightness [2]float64, clustered bool) starLayer {
+	var centres []Vector2
+	if clustered {
+		for i := 0; i < 3; i++ {
+			centres = append(centres, Vector2{rng.Float64() * starTileSize, rng.Float64() * starTileSize})
+		}
+	}
+
+	layer := starLayer{depth: depth, stars: make([]Star, count)}
+	for i := range layer.stars {
+		pos := Vector2{rng.Float64() * starTileSize, rng.Float64() * starTileSize}
+		if len(centres) > 0 && rng.Float64() < 0.4 {
+			c := centres[rng.Intn(len(centres))]
+			pos = Vector2{
+				wrapRange(c.X+rng.NormFloat64()*60, starTileSize),
+				wrapRange(c.Y+rng.NormFloat64()*60, starTileSize),
+			}
+		}
+		layer.stars[i] = Star{
+			pos:        pos,
+			brightness: between(rng.Float64(), brightness),
+			twinkle:    rng.Float64() * 2 * math.Pi,
+			speed:      0.1 + rng.Float64()*0.2,
+			size:       between(rng.Float64(), size),
+		}
+	}
+	return layer
+}
+
+// nebulaColors are the theme colours clouds are tinted with.
+func nebulaColors() []themeColor {
+	colors := append([]themeColor{palette.BackgroundTint}, palette.Meteors...)
+	return append(colors, palette.Stars...)
+}
+
+// newNebula paints clouds of soft translucent circles into a small texture
+// that tiles seamlessly and is scaled up when drawn.
+func newNebula(rng *rand.Rand, clouds int, strength float64) *ebiten.Image {
+	img := ebiten.NewImage(nebulaTexSize, nebulaTexSize)
+	colors := nebulaColors()
+	for i := 0; i < clouds; i++ {
+		tint := colors[rng.Intn(len(colors))]
+		cx, cy := rng.Float64()*nebulaTexSize, rng.Float64()*nebulaTexSize
+		for j := 0; j < 30; j++ {
+			x := cx + rng.NormFloat64()*35
+			y := cy + rng.NormFloat64()*25
+			radius := float32(8 + rng.Float64()*32)
+			a := (0.3 + rng.Float64()*0.7) * strength / 255
+			c := color.RGBA{uint8(float64(tint.R) * a), uint8(float64(tint.G) * a), uint8(float64(tint.B) * a), uint8(255 * a)}
+
+			// Draw the copies that wrap around the edges so the tile is seamless
+			for _, dx := range []float64{-nebulaTexSize, 0, nebulaTexSize} {
+				for _, dy := range []float64{-nebulaTexSize, 0, nebulaTexSize} {
+					vector.DrawFilledCircle(img, float32(wrapRange(x, nebulaTexSize)+dx), float32(wrapRange(y, nebulaTexSize)+dy), radius, c, true)
+				}
+			}
+		}
+	}
+	return img
+}
+
+// newPlanets makes n planets with radii between minR and maxR pixels.
+func newPlanets(rng *rand.Rand, n int, minR, maxR float64) []planet {
+	colors := append([]themeColor{}, palette.Meteors...)
+	planets := make([]planet, n)
+	for i := range planets {
+		radius := minR + rng.Float64()*(maxR-minR)
+		planets[i] = planet{
+			x:     0.1 + rng.Float64()*0.8,
+			y:     0.1 + rng.Float64()*0.8,
+			depth: 0.2 + 0.1*radius/maxR,
+			img:   newPlanetImage(rng, radius, colors[rng.Intn(len(colors))].rgba(), rng.Float64() < 0.35),
+		}
+	}
+	return planets
+}
+
+// newPlanetImage renders a planet lit from the top left, with an optional
+// tilted ring passing behind and in front of it.
+func newPlanetImage(rng *rand.Rand, radius float64, c color.RGBA, ringed bool) *ebiten.Image {
+	const ringScale = 1.8 // ring radius relative to the planet
+	half := math.Ceil(radius*ringScale) + 2
+	size := int(half * 2)
+	cx := float32(half)
+	r := float32(radius)
+
+	// Dim the colour so planets stay in the background
+	body := mixRGBA(c, color.RGBA{0, 0, 0, 255}, 0.45)
+
+	sphere := ebiten.NewImage(size, size)
+	vector.DrawFilledCircle(sphere, cx, cx, r, body, true)
+
+	// Shading and a highlight, kept to the disc with source-atop
+	overlay := ebiten.NewImage(size, size)
+	vector.DrawFilledCircle(overlay, cx+r*0.45, cx+r*0.45, r*1.05, color.RGBA{0, 0, 0, 170}, true)
+	vector.DrawFilledCircle(overlay, cx-r*0.35, cx-r*0.35, r*0.35, color.RGBA{30, 30, 30, 30}, true)
+	sphere.DrawImage(overlay, &ebiten.DrawImageOptions{Blend: ebiten.BlendSourceAtop})
+
+	if !ringed {
+		return sphere
+	}
+
+	// The ring is a circle squashed into an ellipse: its far half is drawn
+	// before the planet and its near half after
+	ring := ebiten.NewImage(size, size)
+	ringColor := mixRGBA(body, color.RGBA{200, 200, 200, 255}, 0.3)
+	ringColor.A = 200
+	vector.StrokeCircle(ring, cx, cx, r*ringScale, r*0.15, ringColor, true)
+
+	tilt := 0.25 + rng.Float64()*0.15
+	drawHalf := func(dst *ebiten.Image, far bool) {
+		rect := image.Rect(0, 0, size, size/2)
+		if !far {
+			rect = image.Rect(0, size/2, size, size)
+		}
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(0, float64(rect.Min.Y)-half)
+		op.GeoM.Scale(1, tilt)
+		op.GeoM.Translate(0, half)
+		dst.DrawImage(ring.SubImage(rect).(*ebiten.Image), op)
+	}
+
+	img := ebiten.NewImage(size, size)
+	drawHalf(img, true)
+	img.DrawImage(sphere, nil)
+	drawHalf(img, false)
+	return img
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Vertices per DrawTriangles call; indices are 16-bit
+const maxBatchVertices = 65532
+
+// ==================== BATCHED RENDERING ====================
+
+// quadBatch collects solid shapes into one vertex buffer so a whole layer
+// (the arena grid, the stars, the meteors, the items, the snake, the
+// particles) is drawn with a single DrawTriangles call instead of one call
+// per shape. Shapes are drawn in the order they were added.
+type quadBatch struct {
+	vertices []ebiten.Vertex
+	indices  []uint16
+
+	// immediate draws every shape on its own, as the game used to, so
+	// the bench command can compare the two
+	immediate bool
+	calls     int // DrawTriangles calls made, for the bench command
+}
+
+// Colours are premultiplied, as color.RGBA is and as ebitenutil.DrawRect
+// treated them, so layers look exactly as they did when drawn one by one
+var batchOptions = &ebiten.DrawTrianglesOptions{ColorScaleMode: ebiten.ColorScaleModePremultipliedAlpha}
+
+// whitePixel is the texture every quad samples; the vertex colours do the
+// rest. It is cut from the middle of a larger image so filtering never
+// picks up transparent edges.
+var whitePixel = func() *ebiten.Image {
+	img := ebiten.NewImage(3, 3)
+	img.Fill(color.White)
+	return img.SubImage(image.Rect(1, 1, 2, 2)).(*ebiten.Image)
+}()
+
+// rect adds an axis-aligned rectangle.
+func (b *quadBatch) rect(dst *ebiten.Image, x, y, w, h float64, c color.RGBA) {
+	b.quad(dst, [4][2]float64{{x, y}, {x + w, y}, {x, y + h}, {x + w, y + h}}, c)
+}
+
+// square adds a size by size square centred on x, y and turned by angle
+// radians.
+func (b *quadBatch) square(dst *ebiten.Image, x, y, size, angle float64, c color.RGBA) {
+	// Half-diagonal vectors to the corners
+	r := size / math.Sqrt2
+	dx, dy := r*math.Cos(angle+math.Pi/4), r*math.Sin(angle+math.Pi/4)
+	b.quad(dst, [4][2]float64{{x - dx, y - dy}, {x + dy, y - dx}, {x - dy, y + dx}, {x + dx, y + dy}}, c)
+}
+
+// line adds a straight line of the given width from x0, y0 to x1, y1 with
+// square ends, like vector.StrokeLine.
+func (b *quadBatch) line(dst *ebiten.Image, x0, y0, x1, y1, width float64, c color.RGBA) {
+	length := math.Hypot(x1-x0, y1-y0)
+	if length == 0 {
+		return
+	}
+	// Half the width, across the line
+	nx, ny := -(y1-y0)/length*width/2, (x1-x0)/length*width/2
+	b.quad(dst, [4][2]float64{{x0 + nx, y0 + ny}, {x1 + nx, y1 + ny}, {x0 - nx, y0 - ny}, {x1 - nx, y1 - ny}}, c)
+}
+
+// circle adds a filled circle as a fan of triangles, with more segments the
+// bigger it is.
+func (b *quadBatch) circle(dst *ebiten.Image, x, y, radius float64, c color.RGBA) {
+	segments := int(math.Max(12, math.Min(48, radius)))
+	b.reserve(dst, segments+1)
+
+	base := uint16(len(b.vertices))
+	b.vertex(x, y, c)
+	for i := 0; i < segments; i++ {
+		angle := 2 * math.Pi * float64(i) / float64(segments)
+		b.vertex(x+radius*math.Cos(angle), y+radius*math.Sin(angle), c)
+	}
+	for i := 0; i < segments; i++ {
+		b.indices = append(b.indices, base, base+1+uint16(i), base+1+uint16((i+1)%segments))
+	}
+
+	if b.immediate {
+		b.flush(dst)
+	}
+}
+
+// quad adds a quadrilateral given as top-left, top-right, bottom-left and
+// bottom-right corners.
+func (b *quadBatch) quad(dst *ebiten.Image, corners [4][2]float64, c color.RGBA) {
+	b.reserve(dst, 4)
+
+	base := uint16(len(b.vertices))
+	for _, corner := range corners {
+		b.vertex(corner[0], corner[1], c)
+	}
+	b.indices = append(b.indices, base, base+1, base+2, base+1, base+3, base+2)
+
+	if b.immediate {
+		b.flush(dst)
+	}
+}
+
+// reserve flushes first if n more vertices would not fit in the buffer.
+func (b *quadBatch) reserve(dst *ebiten.Image, n int) {
+	if len(b.vertices)+n > maxBatchVertices {
+		b.flush(dst)
+	}
+}
+
+func (b *quadBatch) vertex(x, y float64, c color.RGBA) {
+	b.vertices = append(b.vertices, ebiten.Vertex{
+		DstX: float32(x), DstY: float32(y),
+		SrcX: 1, SrcY: 1,
+		ColorR: float32(c.R) / 255, ColorG: float32(c.G) / 255, ColorB: float32(c.B) / 255, ColorA: float32(c.A) / 255,
+	})
+}
+
+// flush draws everything added since the last flush onto dst.
+func (b *quadBatch) flush(dst *ebiten.Image) {
+	if len(b.indices) == 0 {
+		return
+	}
+	dst.DrawTriangles(b.vertices, b.indices, whitePixel, batchOptions)
+	b.calls++
+	b.vertices = b.vertices[:0]
+	b.indices = b.indices[:0]
+}
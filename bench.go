@@ -0,0 +1,168 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const benchWarmupFrames = 60 // skipped before timing each pass
+
+// ==================== RENDER BENCHMARK ====================
+
+// benchPass is one timed run through the same scene.
+type benchPass struct {
+	name      string
+	immediate bool
+	calls     int
+	elapsed   time.Duration
+}
+
+// benchGame renders a busy run with no vsync or tick limit, first drawing
+// every shape on its own and then batched, and times both.
+type benchGame struct {
+	g      *Game
+	length int // snake length laid out at the start of every run
+	frames int
+	passes []benchPass
+	pass   int
+	frame  int
+	start  time.Time
+}
+
+func (b *benchGame) Update() error {
+	if b.pass == len(b.passes) {
+		return ebiten.Termination
+	}
+	g := b.g
+
+	// A steady stream of bursts keeps the particle layer busy
+	g.renderer.update()
+	g.step()
+	g.updateCamera()
+	if g.frame%10 == 0 {
+		g.emit(&deathParticles, g.snake[0], palette.Food.rgba())
+	}
+	if g.over {
+		g.startRun(g.seed)
+		benchSnake(g, b.length)
+	}
+	return nil
+}
+
+// benchSnake lays a snake of n segments out in rows along the bottom of the
+// arena, heading up into open space, so the snake layer is as busy as a
+// late run.
+func benchSnake(g *Game, n int) {
+	if room := g.gridW * (g.gridH - 1); n > room {
+		n = room
+	}
+	if n <= len(g.snake) {
+		return
+	}
+
+	// Built tail first, back and forth along each row, then turned round so
+	// the head ends up on the top row
+	snake := make([]Point, n)
+	for i := range snake {
+		row, col := i/g.gridW, i%g.gridW
+		if row%2 == 1 {
+			col = g.gridW - 1 - col
+		}
+		snake[n-1-i] = Point{col, g.gridH - 1 - row}
+	}
+	g.snake = snake
+	g.trailOpacity = make([]float64, n)
+	g.prevSnake = g.prevSnake[:0]
+	g.dir = Point{0, -1}
+	g.nextDir = g.dir
+	g.placeFood()
+}
+
+func (b *benchGame) Draw(screen *ebiten.Image) {
+	if b.pass == len(b.passes) {
+		return
+	}
+	p := &b.passes[b.pass]
+	batch := &b.g.renderer.batch
+	batch.immediate = p.immediate
+
+	if b.frame == benchWarmupFrames {
+		batch.calls = 0
+		b.start = time.Now()
+	}
+	b.g.Draw(screen)
+	b.frame++
+
+	if b.frame == benchWarmupFrames+b.frames {
+		p.elapsed = time.Since(b.start)
+		p.calls = batch.calls
+		b.pass++
+		b.frame = 0
+	}
+}
+
+func (b *benchGame) Layout(w, h int) (int, int) {
+	return b.g.Layout(w, h)
+}
+
+// runBenchCommand implements "snake bench": it opens a window, renders the
+// same run with and without batching and prints the draw calls and frame
+// time of the background, grid, item, snake and particle layers.
+func runBenchCommand(args []string) int {
+	flags := flag.NewFlagSet("bench", flag.ContinueOnError)
+	arena := flags.String("arena", "200x200", "arena size in cells, WxH")
+	frames := flags.Int("frames", 600, "frames to time for each pass")
+	length := flags.Int("length", 1000, "snake length in segments")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	var w, h int
+	if _, err := fmt.Sscanf(*arena, "%dx%d", &w, &h); err != nil || w < autoMinW || h < autoMinH {
+		fmt.Fprintf(os.Stderr, "bench: arena must be at least %dx%d, got %q\n", autoMinW, autoMinH, *arena)
+		return 2
+	}
+
+	ebiten.SetWindowSize(referenceWidth, referenceHeight)
+	ebiten.SetWindowTitle("Cosmic Snake - bench")
+	ebiten.SetVsyncEnabled(false)
+	ebiten.SetTPS(ebiten.SyncWithFPS)
+
+	// Straight into a run, skipping the title screen and its fade
+	g := NewGame()
+	g.setScene(&playScene{})
+	g.sceneFade = 0
+	if g.bgPlayer != nil {
+		g.bgPlayer.Pause()
+	}
+	g.rules = classicRules
+	g.fixedArena = Point{w, h}
+	g.chooseArena()
+	g.startRun(1)
+	benchSnake(g, *length)
+
+	b := &benchGame{g: g, length: len(g.snake), frames: *frames, passes: []benchPass{
+		{name: "immediate", immediate: true},
+		{name: "batched"},
+	}}
+	if err := ebiten.RunGame(b); err != nil {
+		fmt.Fprintf(os.Stderr, "bench: %v\n", err)
+		return 1
+	}
+	if b.pass < len(b.passes) {
+		fmt.Fprintln(os.Stderr, "bench: window closed before the benchmark finished")
+		return 1
+	}
+
+	fmt.Printf("Arena %dx%d, snake of %d, %d frames per pass at %dx%d\n\n", w, h, b.length, *frames, g.screenWidth, g.screenHeight)
+	fmt.Printf("%-10s %16s %12s\n", "pass", "draw calls/frame", "frame time")
+	for _, p := range b.passes {
+		fmt.Printf("%-10s %16.1f %9.2f ms\n", p.name,
+			float64(p.calls)/float64(*frames),
+			float64(p.elapsed.Microseconds())/1000/float64(*frames))
+	}
+	return 0
+}
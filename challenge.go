@@ -0,0 +1,278 @@
+package main
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	challengeDaily  = "daily"
+	challengeWeekly = "weekly"
+	dateLayout      = "2006-01-02"
+)
+
+// ==================== CHALLENGE TYPES ====================
+
+// Challenge is a run whose seed, arena and rules are all derived from the
+// calendar, so everyone playing on the same day gets the same game.
+type Challenge struct {
+	Kind   string
+	ID     string // first day of the period, YYYY-MM-DD
+	Seed   int64
+	ArenaW int
+	ArenaH int
+	Rules  Rules
+}
+
+// ChallengeResult is one profile's scored attempt at a challenge. It is
+// written when the attempt starts so quitting does not grant a retry.
+type ChallengeResult struct {
+	Kind     string `json:"kind"`
+	ID       string `json:"id"`
+	Score    int    `json:"score"`
+	Length   int    `json:"length"`
+	MaxCombo int    `json:"max_combo"`
+	Finished bool   `json:"finished"`
+}
+
+// ==================== CHALLENGE GENERATION ====================
+
+func challengePeriodStart(kind string, t time.Time) time.Time {
+	y, m, d := t.Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	if kind == challengeWeekly {
+		// Weeks start on Monday
+		day = day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	}
+	return day
+}
+
+func currentChallenge(kind string, now time.Time) Challenge {
+	return challengeFor(kind, challengePeriodStart(kind, now).Format(dateLayout))
+}
+
+// challengeFor derives a challenge from its kind and ID alone.
+func challengeFor(kind, id string) Challenge {
+	h := fnv.New64a()
+	h.Write([]byte(kind + ":" + id))
+	seed := int64(h.Sum64())
+	r := rand.New(rand.NewSource(seed))
+
+	arenas := []Point{{30, 20}, {36, 22}, {40, 24}, {44, 26}, {50, 28}}
+	if kind == challengeWeekly {
+		arenas = arenas[2:]
+	}
+	arena := arenas[r.Intn(len(arenas))]
+
+	rules := Rules{
+		Name:          id,
+		Walls:         r.Float64() < 0.4,
+		GrowthPerFood: 1 + r.Intn(3),
+		PowerUpChance: 0.05 + r.Float64()*0.25,
+		StartSpeed:    8 + r.Intn(5),
+	}
+	total := 0
+	for i := range rules.PowerUpWeights {
+		rules.PowerUpWeights[i] = r.Intn(4)
+		total += rules.PowerUpWeights[i]
+	}
+	if total == 0 {
+		rules.PowerUpWeights[0] = 1
+	}
+
+	// Weekly challenges are the long-form, harder variant
+	if kind == challengeWeekly {
+		rules.Walls = true
+		rules.StartSpeed -= 2
+	}
+
+	return Challenge{
+		Kind:   kind,
+		ID:     id,
+		Seed:   seed,
+		ArenaW: arena.X,
+		ArenaH: arena.Y,
+		Rules:  rules,
+	}
+}
+
+func (c Challenge) Title() string {
+	if c.Kind == challengeWeekly {
+		return tr("challenge.weekly_title", c.ID)
+	}
+	return tr("challenge.daily_title", c.ID)
+}
+
+// ==================== CHALLENGE HISTORY ====================
+
+func (p *Profile) challengeResult(kind, id string) *ChallengeResult {
+	for i := range p.Challenges {
+		if p.Challenges[i].Kind == kind && p.Challenges[i].ID == id {
+			return &p.Challenges[i]
+		}
+	}
+	return nil
+}
+
+// challengeStreaks returns the current and best run of consecutive periods
+// with an attempt. The current streak survives until the next period ends.
+func (p *Profile) challengeStreaks(kind string, now time.Time) (current, best int) {
+	step := 1
+	if kind == challengeWeekly {
+		step = 7
+	}
+
+	played := make(map[string]bool)
+	var days []time.Time
+	for _, r := range p.Challenges {
+		if r.Kind != kind {
+			continue
+		}
+		if d, err := time.Parse(dateLayout, r.ID); err == nil && !played[r.ID] {
+			played[r.ID] = true
+			days = append(days, d)
+		}
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+
+	run := 0
+	for i, d := range days {
+		if i > 0 && d.Equal(days[i-1].AddDate(0, 0, step)) {
+			run++
+		} else {
+			run = 1
+		}
+		if run > best {
+			best = run
+		}
+	}
+
+	day := challengePeriodStart(kind, now)
+	if !played[day.Format(dateLayout)] {
+		day = day.AddDate(0, 0, -step)
+	}
+	for played[day.Format(dateLayout)] {
+		current++
+		day = day.AddDate(0, 0, -step)
+	}
+	return current, best
+}
+
+// ==================== CHALLENGE RUNS ====================
+
+func (g *Game) startChallenge(c Challenge) {
+	g.setScene(&playScene{})
+	g.challenge = &c
+	g.practice = g.profile.challengeResult(c.Kind, c.ID) != nil
+	g.mode = c.Kind
+	g.rules = c.Rules
+	g.fixedArena = Point{c.ArenaW, c.ArenaH}
+	g.chooseArena()
+	g.startRun(c.Seed)
+
+	if !g.practice {
+		g.profile.Challenges = append(g.profile.Challenges, ChallengeResult{Kind: c.Kind, ID: c.ID})
+		g.saveGameData()
+	}
+	g.startMusic()
+}
+
+// restartRun starts another run of the same kind as the one that just ended.
+// Replaying a challenge after its scored attempt is practice only.
+func (g *Game) restartRun() {
+	if g.challenge != nil {
+		g.startChallenge(*g.challenge)
+		return
+	}
+	g.resetGameplay()
+}
+
+func (g *Game) recordChallengeResult() {
+	if g.challenge == nil || g.practice {
+		return
+	}
+	r := g.profile.challengeResult(g.challenge.Kind, g.challenge.ID)
+	if r == nil {
+		return
+	}
+	r.Score = g.score
+	r.Length = len(g.snake)
+	r.MaxCombo = g.maxCombo
+	r.Finished = true
+	g.saveGameData()
+}
+
+// ==================== CHALLENGES SCREEN ====================
+
+func (g *Game) openChallenges() {
+	g.pushScene(&screenScene{update: g.updateChallenges, draw: g.drawChallengesScreen})
+}
+
+var challengeKinds = []string{challengeDaily, challengeWeekly}
+
+func (g *Game) updateChallenges() error {
+	g.updateUI(g.challengesView())
+	return nil
+}
+
+func (g *Game) challengesView() Widget {
+	now := time.Now()
+	cards := make([]Widget, len(challengeKinds))
+	for i, kind := range challengeKinds {
+		c := currentChallenge(kind, now)
+
+		lines := []Widget{&Label{Text: c.Title()}, label(tr("challenge.arena", c.ArenaW, c.ArenaH), palette.UI.Info)}
+		for _, line := range c.Rules.Summary() {
+			lines = append(lines, label(line, palette.UI.Info))
+		}
+
+		status := tr("challenge.not_attempted")
+		statusColor := palette.UI.Highlight
+		if r := g.profile.challengeResult(kind, c.ID); r != nil {
+			status = trn("challenge.attempt_used", r.Score)
+			if !r.Finished {
+				status = tr("challenge.attempt_abandoned")
+			}
+			statusColor = palette.UI.Heading
+		}
+
+		current, best := g.profile.challengeStreaks(kind, now)
+		lines = append(lines,
+			label(status, statusColor),
+			label(tr("challenge.streak", current, best), palette.UI.Accent),
+			Gap(7))
+		cards[i] = &Panel{Spacing: 7, Children: lines}
+	}
+	list := &List{Items: cards, Cursor: &g.challengeCursor, OnSelect: func(i int) {
+		g.startChallenge(currentChallenge(challengeKinds[i], time.Now()))
+	}}
+
+	// Recent daily history
+	children := []Widget{label(tr("challenge.title"), palette.UI.Title), Gap(20), list, label(tr("challenge.recent"), palette.UI.Heading)}
+	shown := 0
+	for i := len(g.profile.Challenges) - 1; i >= 0 && shown < 7; i-- {
+		r := g.profile.Challenges[i]
+		if r.Kind != challengeDaily {
+			continue
+		}
+		children = append(children, label(tr("challenge.result", r.ID, r.Score, r.Length, r.MaxCombo), palette.UI.Text))
+		shown++
+	}
+	if shown == 0 {
+		children = append(children, label(tr("challenge.none_played"), palette.UI.Text))
+	}
+
+	children = append(children, Gap(13), label(tr("challenge.hint"), palette.UI.Item))
+	return &Screen{
+		Backdrop: palette.UI.Overlay,
+		Children: []Widget{&Panel{Anchor: AnchorCenter, Spacing: 7, Children: children}},
+	}
+}
+
+func (g *Game) drawChallengesScreen(screen *ebiten.Image) {
+	g.renderUI(screen, g.challengesView())
+}
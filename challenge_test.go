@@ -0,0 +1,64 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestChallengePeriods(t *testing.T) {
+	tests := []struct {
+		kind string
+		at   string
+		want string
+	}{
+		{challengeDaily, "2024-03-14T23:59:00Z", "2024-03-14"},
+		{challengeDaily, "2024-03-15T00:00:00Z", "2024-03-15"},
+		{challengeWeekly, "2024-03-11T00:00:00Z", "2024-03-11"}, // Monday
+		{challengeWeekly, "2024-03-14T12:00:00Z", "2024-03-11"},
+		{challengeWeekly, "2024-03-17T23:00:00Z", "2024-03-11"}, // Sunday
+		{challengeWeekly, "2024-03-18T00:00:00Z", "2024-03-18"},
+		{challengeWeekly, "2024-01-03T08:00:00Z", "2024-01-01"},
+	}
+	for _, tt := range tests {
+		at, err := time.Parse(time.RFC3339, tt.at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := currentChallenge(tt.kind, at).ID; got != tt.want {
+			t.Errorf("%s at %s: got %s, want %s", tt.kind, tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestChallengeSeedFromDate(t *testing.T) {
+	a := challengeFor(challengeDaily, "2024-03-14")
+	if b := challengeFor(challengeDaily, "2024-03-14"); !reflect.DeepEqual(a, b) {
+		t.Errorf("same day gave different challenges:\n%+v\n%+v", a, b)
+	}
+	if b := challengeFor(challengeDaily, "2024-03-15"); a.Seed == b.Seed {
+		t.Error("consecutive days share a seed")
+	}
+	if b := challengeFor(challengeWeekly, "2024-03-14"); a.Seed == b.Seed {
+		t.Error("daily and weekly challenges share a seed")
+	}
+
+	// Replays of a challenge rebuild its rules from the board key alone
+	key := BoardKey{Mode: challengeDaily, Rules: a.ID, ArenaW: a.ArenaW, ArenaH: a.ArenaH}
+	if got := rulesForKey(key); !reflect.DeepEqual(got, a.Rules) {
+		t.Errorf("rulesForKey = %+v, want %+v", got, a.Rules)
+	}
+}
+
+func TestWeeklyChallengeIsHarder(t *testing.T) {
+	for day := 0; day < 28; day += 7 {
+		id := time.Date(2024, 3, 4+day, 0, 0, 0, 0, time.UTC).Format(dateLayout)
+		c := challengeFor(challengeWeekly, id)
+		if !c.Rules.Walls {
+			t.Errorf("%s: weekly challenge without walls", id)
+		}
+		if c.ArenaW < 40 {
+			t.Errorf("%s: weekly arena %dx%d is smaller than the weekly minimum", id, c.ArenaW, c.ArenaH)
+		}
+	}
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"image/color"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Keys per action; the editor shows one column per slot
+const bindingSlots = 2
+
+// ==================== INPUT ACTIONS ====================
+
+// Action is something the player can do, independent of the key that does it.
+type Action string
+
+const (
+	ActionUp           Action = "up"
+	ActionDown         Action = "down"
+	ActionLeft         Action = "left"
+	ActionRight        Action = "right"
+	ActionConfirm      Action = "confirm"
+	ActionBack         Action = "back"
+	ActionPause        Action = "pause"
+	ActionRestart      Action = "restart"
+	ActionSpeedUp      Action = "speed_up"
+	ActionSpeedDown    Action = "speed_down"
+	ActionFullscreen   Action = "fullscreen"
+	ActionLeaderboards Action = "leaderboards"
+)
+
+// actions lists every action in the order the binding editor shows them.
+var actions = []Action{
+	ActionUp,
+	ActionDown,
+	ActionLeft,
+	ActionRight,
+	ActionConfirm,
+	ActionBack,
+	ActionPause,
+	ActionRestart,
+	ActionSpeedUp,
+	ActionSpeedDown,
+	ActionFullscreen,
+	ActionLeaderboards,
+}
+
+// Label is the action's name in the binding editor.
+func (a Action) Label() string {
+	return tr("action." + string(a))
+}
+
+// Bindings maps each action to the keys that trigger it.
+type Bindings map[Action][]ebiten.Key
+
+// The leaderboards shortcut used to be S, which is also "down"; it is L now.
+var defaultBindings = Bindings{
+	ActionUp:           {ebiten.KeyArrowUp, ebiten.KeyW},
+	ActionDown:         {ebiten.KeyArrowDown, ebiten.KeyS},
+	ActionLeft:         {ebiten.KeyArrowLeft, ebiten.KeyA},
+	ActionRight:        {ebiten.KeyArrowRight, ebiten.KeyD},
+	ActionConfirm:      {ebiten.KeyEnter, ebiten.KeySpace},
+	ActionBack:         {ebiten.KeyEscape},
+	ActionPause:        {ebiten.KeyP},
+	ActionRestart:      {ebiten.KeyR},
+	ActionSpeedUp:      {ebiten.KeyEqual, ebiten.KeyNumpadAdd},
+	ActionSpeedDown:    {ebiten.KeyMinus, ebiten.KeyNumpadSubtract},
+	ActionFullscreen:   {ebiten.KeyF11},
+	ActionLeaderboards: {ebiten.KeyL},
+}
+
+// keysFor returns the active profile's keys for an action, falling back to
+// the defaults for actions the player never rebound.
+func (g *Game) keysFor(a Action) []ebiten.Key {
+	if g.profile != nil {
+		if keys, ok := g.profile.Bindings[a]; ok {
+			return keys
+		}
+	}
+	return defaultBindings[a]
+}
+
+// pressed reports whether any key bound to a, or a controller, triggered
+// the action this frame.
+func (g *Game) pressed(a Action) bool {
+	for _, k := range g.keysFor(a) {
+		if inpututil.IsKeyJustPressed(k) {
+			return true
+		}
+	}
+	return g.padPressed(a)
+}
+
+// keyLabel is a short on-screen name for a key.
+func keyLabel(k ebiten.Key) string {
+	switch k {
+	case ebiten.KeyArrowUp:
+		return tr("key.up")
+	case ebiten.KeyArrowDown:
+		return tr("key.down")
+	case ebiten.KeyArrowLeft:
+		return tr("key.left")
+	case ebiten.KeyArrowRight:
+		return tr("key.right")
+	case ebiten.KeyEnter:
+		return "ENTER"
+	case ebiten.KeySpace:
+		return "SPACE"
+	case ebiten.KeyEscape:
+		return "ESC"
+	case ebiten.KeyEqual:
+		return "+"
+	case ebiten.KeyMinus:
+		return "-"
+	case ebiten.KeyNumpadAdd:
+		return "Num+"
+	case ebiten.KeyNumpadSubtract:
+		return "Num-"
+	}
+	name := k.String()
+	name = strings.TrimPrefix(name, "Digit")
+	return name
+}
+
+// keyHint names the keys for an action for on-screen hints, e.g. "ENTER/SPACE".
+func (g *Game) keyHint(a Action) string {
+	keys := g.keysFor(a)
+	if len(keys) == 0 {
+		return tr("controls.unbound")
+	}
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = keyLabel(k)
+	}
+	return strings.Join(names, "/")
+}
+
+// conflicts returns, for every key bound to more than one action, the
+// actions sharing it.
+func (g *Game) conflicts() map[ebiten.Key][]Action {
+	owners := map[ebiten.Key][]Action{}
+	for _, a := range actions {
+		for _, k := range g.keysFor(a) {
+			owners[k] = append(owners[k], a)
+		}
+	}
+	for k, list := range owners {
+		if len(list) < 2 {
+			delete(owners, k)
+		}
+	}
+	return owners
+}
+
+// typing reports whether a text field has the keyboard, so bound letters
+// must not trigger actions.
+func (g *Game) typing() bool {
+	return g.enteringName || g.profileEdit == profileEditCreate || g.profileEdit == profileEditRename
+}
+
+// ==================== BINDING EDITOR ====================
+
+// The editor itself always answers to the arrow keys, Enter and Escape, not
+// the bound actions, so a bad binding can never lock the player out of
+// fixing it or of leaving the editor.
+
+func (g *Game) openControls() {
+	g.controlsCursor = 0
+	g.controlsSlot = 0
+	g.capturing = false
+	g.controlsMessage = ""
+	g.editingControls = true
+	g.pushScene(&screenScene{update: g.updateControls, draw: g.drawControlsScreen,
+		exit: func() { g.editingControls = false }})
+}
+
+func (g *Game) setBinding(a Action, slot int, key ebiten.Key, clear bool) {
+	if g.profile.Bindings == nil {
+		g.profile.Bindings = Bindings{}
+	}
+	keys := append([]ebiten.Key(nil), g.keysFor(a)...)
+	for len(keys) <= slot {
+		keys = append(keys, -1)
+	}
+	if clear {
+		keys[slot] = -1
+	} else {
+		keys[slot] = key
+	}
+
+	// Drop empty slots and duplicates within the action
+	kept := keys[:0]
+	for _, k := range keys {
+		dup := false
+		for _, seen := range kept {
+			dup = dup || seen == k
+		}
+		if k >= 0 && !dup {
+			kept = append(kept, k)
+		}
+	}
+	g.profile.Bindings[a] = kept
+	g.saveGameData()
+}
+
+func (g *Game) updateControls() error {
+	a := actions[g.controlsCursor]
+
+	if g.capturing {
+		for _, k := range inpututil.AppendJustPressedKeys(nil) {
+			g.capturing = false
+			if k == ebiten.KeyEscape {
+				g.controlsMessage = tr("controls.cancelled")
+				return nil
+			}
+			g.setBinding(a, g.controlsSlot, k, false)
+			g.controlsMessage = tr("controls.bound", a.Label(), keyLabel(k))
+			if others := g.conflicts()[k]; len(others) > 1 {
+				g.controlsMessage = tr("controls.conflict", keyLabel(k))
+			}
+			return nil
+		}
+		return nil
+	}
+
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowUp):
+		g.controlsCursor = (g.controlsCursor - 1 + len(actions)) % len(actions)
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowDown):
+		g.controlsCursor = (g.controlsCursor + 1) % len(actions)
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft), inpututil.IsKeyJustPressed(ebiten.KeyArrowRight):
+		g.controlsSlot = (g.controlsSlot + 1) % bindingSlots
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		g.capturing = true
+		g.controlsMessage = ""
+	case inpututil.IsKeyJustPressed(ebiten.KeyDelete), inpututil.IsKeyJustPressed(ebiten.KeyBackspace):
+		g.setBinding(a, g.controlsSlot, 0, true)
+	case inpututil.IsKeyJustPressed(ebiten.KeyF5):
+		g.profile.Bindings = nil
+		g.saveGameData()
+		g.controlsMessage = tr("controls.reset")
+	}
+	return nil
+}
+
+// The editor keeps its own two-axis cursor, so it draws with the toolkit but
+// reads input itself.
+func (g *Game) controlsView() Widget {
+	columns := []float64{280, 120, 120}
+	children := []Widget{
+		label(tr("controls.title", g.profile.Name), palette.UI.Title),
+		Gap(20),
+		&Row{Widths: columns, Cells: []Widget{
+			&Label{Text: "  " + tr("controls.action"), Color: palette.UI.Heading, Align: AnchorLeft},
+			&Label{Text: tr("controls.key", 1), Color: palette.UI.Heading, Align: AnchorLeft},
+			&Label{Text: tr("controls.key", 2), Color: palette.UI.Heading, Align: AnchorLeft},
+		}},
+	}
+
+	conflicts := g.conflicts()
+	for i, a := range actions {
+		var rowColor color.Color = palette.UI.Item
+		name := "  " + a.Label()
+		if i == g.controlsCursor {
+			rowColor = palette.UI.Selected
+			name = "► " + a.Label()
+		}
+		row := &Row{Widths: columns, Cells: []Widget{&Label{Text: name, Color: rowColor, Align: AnchorLeft}}}
+
+		keys := g.keysFor(a)
+		for slot := 0; slot < bindingSlots; slot++ {
+			label := "---"
+			c := rowColor
+			if slot < len(keys) {
+				label = keyLabel(keys[slot])
+				if len(conflicts[keys[slot]]) > 1 {
+					c = palette.UI.Danger
+				}
+			}
+			if i == g.controlsCursor && slot == g.controlsSlot {
+				if g.capturing {
+					label = tr("controls.press_key")
+				}
+				label = "[" + label + "]"
+			}
+			row.Cells = append(row.Cells, &Label{Text: label, Color: c, Align: AnchorLeft})
+		}
+		children = append(children, row)
+	}
+
+	children = append(children, Gap(12))
+	if len(conflicts) > 0 {
+		children = append(children, label(tr("controls.conflicts"), palette.UI.Danger))
+	}
+	if g.controlsMessage != "" {
+		children = append(children, label(g.controlsMessage, palette.UI.Accent))
+	}
+	hint := tr("controls.hint")
+	if g.capturing {
+		hint = tr("controls.capture_hint")
+	}
+	children = append(children, Gap(12), label(hint, palette.UI.Text))
+
+	return &Screen{
+		Backdrop: palette.UI.Overlay,
+		Children: []Widget{&Panel{Anchor: AnchorCenter, Spacing: 11, Children: children}},
+	}
+}
+
+func (g *Game) drawControlsScreen(screen *ebiten.Image) {
+	g.renderUI(screen, g.controlsView())
+}
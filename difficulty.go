@@ -0,0 +1,189 @@
+package main
+
+import (
+	"strings"
+)
+
+const hazardSafeRadius = 4 // hazards never spawn this close to the head
+
+// ==================== DIFFICULTY PRESETS ====================
+
+// Difficulty is an optional progression curve. The level rises with score or
+// length, whichever is further along, and each level speeds the snake up,
+// drops more asteroid hazards and makes power-ups rarer.
+type Difficulty struct {
+	ID string
+
+	PointsPerLevel  int     // score needed per level
+	LengthPerLevel  int     // extra segments needed per level
+	SpeedEvery      int     // levels per one-frame speed-up, 0 for none
+	StartSpeedDelta int     // added to the rules' starting speed
+	HazardsPerLevel int     // asteroids dropped on every level-up
+	PowerUpDecay    float64 // power-up chance lost per level
+	MaxLevel        int
+}
+
+var difficulties = []Difficulty{
+	{ID: "off"},
+	{
+		ID:             "casual",
+		PointsPerLevel: 12,
+		LengthPerLevel: 20,
+		SpeedEvery:     2,
+		MaxLevel:       10,
+	},
+	{
+		ID:              "classic",
+		PointsPerLevel:  8,
+		LengthPerLevel:  15,
+		SpeedEvery:      1,
+		HazardsPerLevel: 1,
+		PowerUpDecay:    0.05,
+		MaxLevel:        15,
+	},
+	{
+		ID:              "insane",
+		PointsPerLevel:  5,
+		LengthPerLevel:  10,
+		SpeedEvery:      1,
+		StartSpeedDelta: -2,
+		HazardsPerLevel: 3,
+		PowerUpDecay:    0.1,
+		MaxLevel:        25,
+	},
+}
+
+func difficultyByID(id string) Difficulty {
+	for _, d := range difficulties {
+		if d.ID == id {
+			return d
+		}
+	}
+	return difficulties[0]
+}
+
+func (d Difficulty) Title() string {
+	return tr("difficulty." + d.ID)
+}
+
+func (d Difficulty) progressive() bool {
+	return d.PointsPerLevel > 0 || d.LengthPerLevel > 0
+}
+
+// rulesName is the leaderboard bucket for endless-style rules played at this
+// difficulty. "off" keeps the original "classic" bucket.
+func (d Difficulty) rulesName() string {
+	if !d.progressive() {
+		return classicRules.Name
+	}
+	return "curve-" + d.ID
+}
+
+func difficultyFromRulesName(name string) (Difficulty, bool) {
+	if !strings.HasPrefix(name, "curve-") {
+		return Difficulty{}, false
+	}
+	return difficultyByID(strings.TrimPrefix(name, "curve-")), true
+}
+
+// ==================== PROGRESSION ====================
+
+func (g *Game) levelFor(score, length int) int {
+	d := g.difficulty
+	if !d.progressive() {
+		return 1
+	}
+	level := 1
+	if d.PointsPerLevel > 0 && 1+score/d.PointsPerLevel > level {
+		level = 1 + score/d.PointsPerLevel
+	}
+	if d.LengthPerLevel > 0 && 1+(length-3)/d.LengthPerLevel > level {
+		level = 1 + (length-3)/d.LengthPerLevel
+	}
+	if level > d.MaxLevel {
+		level = d.MaxLevel
+	}
+	return level
+}
+
+// updateLevel applies any level-ups earned since the last move.
+func (g *Game) updateLevel() {
+	target := g.levelFor(g.score, len(g.snake)+g.grow)
+	for g.level < target {
+		g.level++
+		d := g.difficulty
+
+		if d.SpeedEvery > 0 && (g.level-1)%d.SpeedEvery == 0 && g.baseSpeed > minSpeed {
+			g.baseSpeed--
+		}
+		for i := 0; i < d.HazardsPerLevel; i++ {
+			g.placeHazard()
+		}
+
+		g.levelUpTimer = 120
+		g.playSound(g.powerUpPlayer)
+		g.emit(&levelUpParticles, g.snake[0], palette.UI.Accent.rgba())
+	}
+}
+
+// powerUpChance is the rules' spawn chance reduced by the current level.
+func (g *Game) powerUpChance() float64 {
+	chance := g.rules.PowerUpChance * (1 - g.difficulty.PowerUpDecay*float64(g.level-1))
+	if floor := g.rules.PowerUpChance * 0.2; chance < floor {
+		return floor
+	}
+	return chance
+}
+
+// ==================== HAZARDS ====================
+
+func (g *Game) isHazard(p Point) bool {
+	for _, h := range g.hazards {
+		if h == p {
+			return true
+		}
+	}
+	return false
+}
+
+// placeHazard drops an asteroid on a free cell away from the head. It gives
+// up after a bounded number of attempts on crowded arenas.
+func (g *Game) placeHazard() {
+	head := g.snake[0]
+	for attempt := 0; attempt < 100; attempt++ {
+		p := Point{g.rng.Intn(g.gridW), g.rng.Intn(g.gridH)}
+		dx, dy := p.X-head.X, p.Y-head.Y
+		if dx*dx+dy*dy < hazardSafeRadius*hazardSafeRadius {
+			continue
+		}
+		if p == g.food || (g.powerUp.active && p == g.powerUp.pos) || g.isHazard(p) {
+			continue
+		}
+		occupied := false
+		for _, s := range g.snake {
+			if s == p {
+				occupied = true
+				break
+			}
+		}
+		if !occupied {
+			g.hazards = append(g.hazards, p)
+			return
+		}
+	}
+}
+
+func (g *Game) levelHUDLine() string {
+	if !g.difficulty.progressive() {
+		return ""
+	}
+	if g.level >= g.difficulty.MaxLevel {
+		return tr("hud.level_max", g.level, g.difficulty.Title())
+	}
+	next := g.level * g.difficulty.PointsPerLevel
+	line := tr("hud.level", g.level, g.difficulty.Title(), next)
+	if g.levelUpTimer > 0 {
+		line += "  " + tr("hud.level_up")
+	}
+	return line
+}
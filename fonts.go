@@ -0,0 +1,266 @@
+package main
+
+import (
+	"image/color"
+	"log"
+	"math"
+	"unicode/utf8"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/opentype"
+)
+
+const (
+	uiFontSize = 12.0 // pixels at the reference window size
+
+	// Window size the UI is designed at; larger windows scale it up
+	referenceWidth  = 1280
+	referenceHeight = 720
+)
+
+// ==================== FONTS ====================
+
+// The UI font is Go Mono, embedded in the binary. It is monospaced like the
+// bitmap font it replaced, so tables still line up, and it covers Latin,
+// Greek and Cyrillic. Faces are rasterised at the size they are drawn, so
+// text stays sharp at every window size and UI scale.
+
+var (
+	uiFont    *opentype.Font
+	fontFaces = map[int]font.Face{}
+)
+
+// fontFace returns the UI font at size pixels, falling back to the bitmap
+// font if the TTF cannot be loaded.
+func fontFace(size float64) font.Face {
+	key := int(math.Round(size * 4))
+	if face, ok := fontFaces[key]; ok {
+		return face
+	}
+
+	var face font.Face = basicfont.Face7x13
+	if uiFont == nil {
+		f, err := opentype.Parse(gomono.TTF)
+		if err != nil {
+			log.Printf("Could not load UI font: %v", err)
+		}
+		uiFont = f
+	}
+	if uiFont != nil {
+		f, err := opentype.NewFace(uiFont, &opentype.FaceOptions{
+			Size:    float64(key) / 4,
+			DPI:     72,
+			Hinting: font.HintingFull,
+		})
+		if err == nil {
+			face = f
+		}
+	}
+	fontFaces[key] = face
+	return face
+}
+
+// viewScale is how much larger than the reference size the window is. It
+// never shrinks the UI below its designed size.
+func (g *Game) viewScale() float64 {
+	s := math.Min(float64(g.screenWidth)/referenceWidth, float64(g.screenHeight)/referenceHeight)
+	return math.Max(1, s)
+}
+
+// ==================== TEXT WITH ICONS ====================
+
+// Emoji have no glyphs in the UI font, so the ones the game uses are drawn
+// as small vector icons, one line high, in their own colours.
+
+type iconFunc func(dst *ebiten.Image, x, y, s float32)
+
+var icons = map[rune]iconFunc{
+	'🚀': drawRocketIcon,
+	'🛡': drawShieldIcon,
+	'💰': drawCoinIcon,
+	'🐌': drawSnailIcon,
+	'🌌': drawGalaxyIcon,
+	'🐍': drawSnakeIcon,
+	'🔥': drawFireIcon,
+	'🎯': drawTargetIcon,
+	'🏆': drawTrophyIcon,
+	'👤': drawPersonIcon,
+	'⏸': drawPauseIcon,
+	'🔓': drawUnlockIcon,
+	'⏱': drawStopwatchIcon,
+	'💀': drawSkullIcon,
+}
+
+// Emoji presentation selector, as in "🛡️"; it has no width of its own
+const variationSelector = '\uFE0F'
+
+// textSpans splits s into runs of font text and single icons.
+func textSpans(s string, fn func(run string, icon iconFunc)) {
+	start := 0
+	for i, r := range s {
+		icon, isIcon := icons[r]
+		if !isIcon && r != variationSelector {
+			continue
+		}
+		if start < i {
+			fn(s[start:i], nil)
+		}
+		if isIcon {
+			fn("", icon)
+		}
+		start = i + utf8.RuneLen(r)
+	}
+	if start < len(s) {
+		fn(s[start:], nil)
+	}
+}
+
+// iconSize is the width and height of an icon drawn with face.
+func iconSize(face font.Face) float64 {
+	return float64(face.Metrics().Height.Ceil())
+}
+
+// measureText is the width of s drawn with drawText.
+func measureText(face font.Face, s string) float64 {
+	w := 0.0
+	textSpans(s, func(run string, icon iconFunc) {
+		if icon != nil {
+			w += iconSize(face)
+		} else {
+			w += float64(font.MeasureString(face, run).Ceil())
+		}
+	})
+	return w
+}
+
+// drawText draws s with its top-left corner at x, y.
+func drawText(dst *ebiten.Image, face font.Face, s string, x, y float64, c color.Color) {
+	ascent := face.Metrics().Ascent.Ceil()
+	size := iconSize(face)
+	textSpans(s, func(run string, icon iconFunc) {
+		if icon != nil {
+			icon(dst, float32(x), float32(y), float32(size))
+			x += size
+			return
+		}
+		text.Draw(dst, run, face, int(x), int(y)+ascent, c)
+		x += float64(font.MeasureString(face, run).Ceil())
+	})
+}
+
+// ==================== ICONS ====================
+
+// Each icon fills an s by s box with its top-left corner at x, y.
+
+var (
+	iconOrange = color.RGBA{255, 140, 40, 255}
+	iconYellow = color.RGBA{255, 210, 60, 255}
+	iconGold   = color.RGBA{230, 180, 40, 255}
+	iconBlue   = color.RGBA{80, 150, 255, 255}
+	iconGreen  = color.RGBA{90, 200, 90, 255}
+	iconBrown  = color.RGBA{170, 110, 60, 255}
+	iconPurple = color.RGBA{140, 90, 220, 255}
+	iconRed    = color.RGBA{230, 60, 60, 255}
+	iconGrey   = color.RGBA{200, 200, 210, 255}
+)
+
+func drawRocketIcon(dst *ebiten.Image, x, y, s float32) {
+	w := s * 0.12
+	vector.StrokeLine(dst, x+s*0.25, y+s*0.75, x+s*0.8, y+s*0.2, s*0.28, iconGrey, true)
+	vector.StrokeLine(dst, x+s*0.7, y+s*0.3, x+s*0.9, y+s*0.1, w, iconRed, true)
+	vector.DrawFilledCircle(dst, x+s*0.55, y+s*0.45, s*0.08, iconBlue, true)
+	vector.StrokeLine(dst, x+s*0.25, y+s*0.75, x+s*0.08, y+s*0.92, w*1.5, iconOrange, true)
+}
+
+func drawShieldIcon(dst *ebiten.Image, x, y, s float32) {
+	vector.DrawFilledRect(dst, x+s*0.2, y+s*0.12, s*0.6, s*0.45, iconBlue, true)
+	vector.DrawFilledCircle(dst, x+s*0.5, y+s*0.55, s*0.3, iconBlue, true)
+	vector.StrokeLine(dst, x+s*0.5, y+s*0.2, x+s*0.5, y+s*0.78, s*0.08, iconGrey, true)
+}
+
+func drawCoinIcon(dst *ebiten.Image, x, y, s float32) {
+	vector.DrawFilledCircle(dst, x+s*0.5, y+s*0.5, s*0.4, iconGold, true)
+	vector.StrokeCircle(dst, x+s*0.5, y+s*0.5, s*0.28, s*0.07, iconYellow, true)
+}
+
+func drawSnailIcon(dst *ebiten.Image, x, y, s float32) {
+	vector.DrawFilledRect(dst, x+s*0.1, y+s*0.72, s*0.8, s*0.14, iconGreen, true)
+	vector.DrawFilledCircle(dst, x+s*0.45, y+s*0.5, s*0.28, iconBrown, true)
+	vector.StrokeCircle(dst, x+s*0.45, y+s*0.5, s*0.12, s*0.06, iconYellow, true)
+	vector.StrokeLine(dst, x+s*0.8, y+s*0.72, x+s*0.88, y+s*0.4, s*0.07, iconGreen, true)
+}
+
+func drawGalaxyIcon(dst *ebiten.Image, x, y, s float32) {
+	vector.DrawFilledCircle(dst, x+s*0.5, y+s*0.5, s*0.42, iconPurple, true)
+	for _, p := range [][2]float32{{0.3, 0.35}, {0.62, 0.28}, {0.5, 0.55}, {0.7, 0.68}, {0.35, 0.7}} {
+		vector.DrawFilledCircle(dst, x+s*p[0], y+s*p[1], s*0.05, color.White, true)
+	}
+}
+
+func drawSnakeIcon(dst *ebiten.Image, x, y, s float32) {
+	w := s * 0.18
+	vector.StrokeLine(dst, x+s*0.15, y+s*0.8, x+s*0.45, y+s*0.6, w, iconGreen, true)
+	vector.StrokeLine(dst, x+s*0.45, y+s*0.6, x+s*0.3, y+s*0.35, w, iconGreen, true)
+	vector.StrokeLine(dst, x+s*0.3, y+s*0.35, x+s*0.7, y+s*0.2, w, iconGreen, true)
+	vector.DrawFilledCircle(dst, x+s*0.75, y+s*0.2, s*0.13, iconGreen, true)
+	vector.StrokeLine(dst, x+s*0.85, y+s*0.22, x+s*0.97, y+s*0.3, s*0.05, iconRed, true)
+}
+
+func drawFireIcon(dst *ebiten.Image, x, y, s float32) {
+	vector.DrawFilledCircle(dst, x+s*0.5, y+s*0.62, s*0.3, iconOrange, true)
+	vector.StrokeLine(dst, x+s*0.5, y+s*0.62, x+s*0.5, y+s*0.1, s*0.28, iconOrange, true)
+	vector.DrawFilledCircle(dst, x+s*0.5, y+s*0.7, s*0.15, iconYellow, true)
+}
+
+func drawTargetIcon(dst *ebiten.Image, x, y, s float32) {
+	vector.DrawFilledCircle(dst, x+s*0.5, y+s*0.5, s*0.42, iconRed, true)
+	vector.DrawFilledCircle(dst, x+s*0.5, y+s*0.5, s*0.28, color.White, true)
+	vector.DrawFilledCircle(dst, x+s*0.5, y+s*0.5, s*0.14, iconRed, true)
+}
+
+func drawTrophyIcon(dst *ebiten.Image, x, y, s float32) {
+	vector.DrawFilledRect(dst, x+s*0.25, y+s*0.1, s*0.5, s*0.35, iconGold, true)
+	vector.DrawFilledCircle(dst, x+s*0.5, y+s*0.42, s*0.25, iconGold, true)
+	vector.StrokeCircle(dst, x+s*0.22, y+s*0.3, s*0.1, s*0.06, iconGold, true)
+	vector.StrokeCircle(dst, x+s*0.78, y+s*0.3, s*0.1, s*0.06, iconGold, true)
+	vector.DrawFilledRect(dst, x+s*0.44, y+s*0.6, s*0.12, s*0.2, iconGold, true)
+	vector.DrawFilledRect(dst, x+s*0.28, y+s*0.8, s*0.44, s*0.1, iconGold, true)
+}
+
+func drawPersonIcon(dst *ebiten.Image, x, y, s float32) {
+	vector.DrawFilledCircle(dst, x+s*0.5, y+s*0.32, s*0.18, iconGrey, true)
+	vector.DrawFilledCircle(dst, x+s*0.5, y+s*0.75, s*0.3, iconGrey, true)
+	vector.DrawFilledRect(dst, x+s*0.2, y+s*0.75, s*0.6, s*0.2, iconGrey, true)
+}
+
+func drawPauseIcon(dst *ebiten.Image, x, y, s float32) {
+	vector.DrawFilledRect(dst, x+s*0.22, y+s*0.15, s*0.2, s*0.7, iconBlue, true)
+	vector.DrawFilledRect(dst, x+s*0.58, y+s*0.15, s*0.2, s*0.7, iconBlue, true)
+}
+
+func drawUnlockIcon(dst *ebiten.Image, x, y, s float32) {
+	vector.StrokeCircle(dst, x+s*0.62, y+s*0.32, s*0.18, s*0.08, iconGrey, true)
+	vector.DrawFilledRect(dst, x+s*0.18, y+s*0.45, s*0.5, s*0.45, iconGold, true)
+}
+
+func drawStopwatchIcon(dst *ebiten.Image, x, y, s float32) {
+	vector.DrawFilledRect(dst, x+s*0.42, y+s*0.05, s*0.16, s*0.12, iconGrey, true)
+	vector.StrokeCircle(dst, x+s*0.5, y+s*0.55, s*0.35, s*0.08, iconGrey, true)
+	vector.StrokeLine(dst, x+s*0.5, y+s*0.55, x+s*0.5, y+s*0.32, s*0.07, iconRed, true)
+	vector.StrokeLine(dst, x+s*0.5, y+s*0.55, x+s*0.68, y+s*0.55, s*0.07, iconRed, true)
+}
+
+func drawSkullIcon(dst *ebiten.Image, x, y, s float32) {
+	vector.DrawFilledCircle(dst, x+s*0.5, y+s*0.42, s*0.34, iconGrey, true)
+	vector.DrawFilledRect(dst, x+s*0.3, y+s*0.6, s*0.4, s*0.28, iconGrey, true)
+	vector.DrawFilledCircle(dst, x+s*0.37, y+s*0.45, s*0.09, color.Black, true)
+	vector.DrawFilledCircle(dst, x+s*0.63, y+s*0.45, s*0.09, color.Black, true)
+	vector.StrokeLine(dst, x+s*0.43, y+s*0.72, x+s*0.43, y+s*0.88, s*0.05, color.Black, true)
+	vector.StrokeLine(dst, x+s*0.57, y+s*0.72, x+s*0.57, y+s*0.88, s*0.05, color.Black, true)
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const (
+	maxPlayers      = 4
+	defaultDeadzone = 25 // percent of full stick travel
+	padToastFrames  = 180
+)
+
+// ==================== GAMEPADS ====================
+
+// gamepad is a connected controller and the player it is assigned to.
+// Player 1 steers the snake; the other slots are reserved for local
+// multiplayer and can still drive the menus.
+type gamepad struct {
+	id     ebiten.GamepadID
+	name   string
+	sdlID  string
+	player int // 1-based
+
+	stick, prevStick Point // left stick direction, this and last frame
+}
+
+// Standard layout buttons for each action. Movement also accepts the stick.
+var padButtons = map[Action][]ebiten.StandardGamepadButton{
+	ActionUp:           {ebiten.StandardGamepadButtonLeftTop},
+	ActionDown:         {ebiten.StandardGamepadButtonLeftBottom},
+	ActionLeft:         {ebiten.StandardGamepadButtonLeftLeft},
+	ActionRight:        {ebiten.StandardGamepadButtonLeftRight},
+	ActionConfirm:      {ebiten.StandardGamepadButtonRightBottom},
+	ActionBack:         {ebiten.StandardGamepadButtonRightRight},
+	ActionPause:        {ebiten.StandardGamepadButtonCenterRight},
+	ActionRestart:      {ebiten.StandardGamepadButtonRightTop},
+	ActionSpeedUp:      {ebiten.StandardGamepadButtonFrontTopRight},
+	ActionSpeedDown:    {ebiten.StandardGamepadButtonFrontTopLeft},
+	ActionLeaderboards: {ebiten.StandardGamepadButtonCenterLeft},
+}
+
+// stickDirs is the stick direction that triggers each movement action.
+var stickDirs = map[Action]Point{
+	ActionUp:    {0, -1},
+	ActionDown:  {0, 1},
+	ActionLeft:  {-1, 0},
+	ActionRight: {1, 0},
+}
+
+// rawButtons maps standard buttons to the usual raw indices for controllers
+// without a standard layout mapping.
+var rawButtons = map[ebiten.StandardGamepadButton]ebiten.GamepadButton{
+	ebiten.StandardGamepadButtonRightBottom:   ebiten.GamepadButton0,
+	ebiten.StandardGamepadButtonRightRight:    ebiten.GamepadButton1,
+	ebiten.StandardGamepadButtonRightLeft:     ebiten.GamepadButton2,
+	ebiten.StandardGamepadButtonRightTop:      ebiten.GamepadButton3,
+	ebiten.StandardGamepadButtonFrontTopLeft:  ebiten.GamepadButton4,
+	ebiten.StandardGamepadButtonFrontTopRight: ebiten.GamepadButton5,
+	ebiten.StandardGamepadButtonCenterLeft:    ebiten.GamepadButton6,
+	ebiten.StandardGamepadButtonCenterRight:   ebiten.GamepadButton7,
+}
+
+// updateGamepads handles hot-plugging and samples the sticks. It runs once
+// per frame before any input is read.
+func (g *Game) updateGamepads() {
+	for _, id := range inpututil.AppendJustConnectedGamepadIDs(nil) {
+		g.connectPad(id)
+	}
+
+	for i := len(g.pads) - 1; i >= 0; i-- {
+		p := g.pads[i]
+		if !inpututil.IsGamepadJustDisconnected(p.id) {
+			continue
+		}
+		g.pads = append(g.pads[:i], g.pads[i+1:]...)
+		g.padToast(tr("pads.disconnected", p.player))
+
+		// Losing the steering controller mid-run pauses the game
+		if p.player == 1 && g.playing() {
+			g.pushScene(&pauseScene{})
+		}
+	}
+
+	for _, p := range g.pads {
+		p.prevStick = p.stick
+		p.stick = g.stickDirection(p.id)
+	}
+
+	if g.padToastTimer > 0 {
+		g.padToastTimer--
+	}
+}
+
+func (g *Game) connectPad(id ebiten.GamepadID) {
+	p := &gamepad{
+		id:    id,
+		name:  ebiten.GamepadName(id),
+		sdlID: ebiten.GamepadSDLID(id),
+	}
+
+	// Controllers keep their saved player; new ones take the first free slot
+	if player, ok := g.settings.PadPlayers[p.sdlID]; ok {
+		p.player = player
+	} else {
+		p.player = 1
+		for slot := 1; slot <= maxPlayers; slot++ {
+			if !g.playerHasPad(slot) {
+				p.player = slot
+				break
+			}
+		}
+	}
+	g.pads = append(g.pads, p)
+	g.padToast(tr("pads.connected", p.name, p.player))
+}
+
+func (g *Game) playerHasPad(player int) bool {
+	for _, p := range g.pads {
+		if p.player == player {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Game) padToast(msg string) {
+	g.padMessage = msg
+	g.padToastTimer = padToastFrames
+}
+
+// stickDirection is the left stick's dominant direction, or zero inside the
+// deadzone.
+func (g *Game) stickDirection(id ebiten.GamepadID) Point {
+	var x, y float64
+	if ebiten.IsStandardGamepadLayoutAvailable(id) {
+		x = ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickHorizontal)
+		y = ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickVertical)
+	} else if ebiten.GamepadAxisCount(id) >= 2 {
+		x = ebiten.GamepadAxisValue(id, 0)
+		y = ebiten.GamepadAxisValue(id, 1)
+	}
+
+	deadzone := float64(g.settings.Deadzone) / 100
+	if math.Abs(x) < deadzone && math.Abs(y) < deadzone {
+		return Point{}
+	}
+	return dominantDir(x, y)
+}
+
+// pressed reports whether the controller triggered an action this frame.
+func (p *gamepad) pressed(a Action) bool {
+	// The stick acts like a D-pad that fires when it enters a direction
+	if d, ok := stickDirs[a]; ok && p.stick == d && p.prevStick != d {
+		return true
+	}
+
+	standard := ebiten.IsStandardGamepadLayoutAvailable(p.id)
+	for _, b := range padButtons[a] {
+		if standard {
+			if inpututil.IsStandardGamepadButtonJustPressed(p.id, b) {
+				return true
+			}
+		} else if raw, ok := rawButtons[b]; ok && inpututil.IsGamepadButtonJustPressed(p.id, raw) {
+			return true
+		}
+	}
+	return false
+}
+
+// padPressed checks the controllers that may act right now: during a run
+// only Player 1 steers, everywhere else any controller can drive the menus.
+func (g *Game) padPressed(a Action) bool {
+	for _, p := range g.pads {
+		if g.playing() && p.player != 1 {
+			continue
+		}
+		if p.pressed(a) {
+			return true
+		}
+	}
+	return false
+}
+
+// ==================== CONTROLLERS SCREEN ====================
+
+func (g *Game) openControllers() {
+	g.padCursor = 0
+	g.pushScene(&screenScene{update: g.updateControllers, draw: g.drawControllersScreen})
+}
+
+// updateControllers assigns the selected controller to a player with
+// Left/Right. The deadzone row sits below the controller list.
+func (g *Game) updateControllers() error {
+	g.updateUI(g.controllersView())
+	return nil
+}
+
+func (g *Game) controllersView() Widget {
+	rows := make([]Widget, 0, len(g.pads)+1)
+	for _, p := range g.pads {
+		layout := tr("pads.standard")
+		if !ebiten.IsStandardGamepadLayoutAvailable(p.id) {
+			layout = tr("pads.generic")
+		}
+		// Only Player 1 plays for now; the other slots are kept for local
+		// multiplayer and just drive the menus
+		value := tr("pads.player", p.player)
+		if p.player != 1 {
+			value = tr("pads.player_reserved", p.player)
+		}
+		rows = append(rows, &Choice{
+			Label: fmt.Sprintf("%s (%s)", p.name, layout),
+			Value: value,
+			OnChange: func(d int) {
+				p.player = (p.player-1+d+maxPlayers)%maxPlayers + 1
+				if g.settings.PadPlayers == nil {
+					g.settings.PadPlayers = map[string]int{}
+				}
+				g.settings.PadPlayers[p.sdlID] = p.player
+			},
+		})
+	}
+	rows = append(rows, &Slider{
+		Label: tr("pads.deadzone"),
+		Value: float64(g.settings.Deadzone) / 60,
+		Text:  fmt.Sprintf("%d%%", g.settings.Deadzone),
+		OnChange: func(d int) {
+			g.settings.Deadzone += d * 5
+			if g.settings.Deadzone < 5 {
+				g.settings.Deadzone = 5
+			}
+			if g.settings.Deadzone > 60 {
+				g.settings.Deadzone = 60
+			}
+		},
+	})
+
+	hints := []string{
+		tr("pads.player_one", maxPlayers),
+		tr("pads.buttons"),
+		tr("pads.hint"),
+	}
+	if len(g.pads) == 0 {
+		hints = append([]string{tr("pads.none")}, hints...)
+	}
+	list := &List{Items: rows, Cursor: &g.padCursor, RowHeight: 30, OnChange: g.saveSettings}
+	return g.optionScreen(tr("pads.title"), list, hints...)
+}
+
+func (g *Game) drawControllersScreen(screen *ebiten.Image) {
+	g.renderUI(screen, g.controllersView())
+}
+
+// drawPadToast shows connect and disconnect notices on top of every screen.
+func (g *Game) drawPadToast(screen *ebiten.Image) {
+	g.renderUI(screen, &Panel{
+		Anchor:     AnchorBottomRight,
+		Margin:     15,
+		Padding:    8,
+		Background: palette.UI.HUDBackground,
+		Children:   []Widget{label(g.padMessage, palette.UI.Accent)},
+	})
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const localesDir = "assets/locales"
+
+// ==================== LOCALIZATION ====================
+
+// Locale is a language's message catalogue. Messages are keyed by ID;
+// counted messages have one entry per plural category, e.g. "games.one"
+// and "games.other", and the locale's plural rule picks between them.
+// Anything a locale leaves out falls back to English.
+type Locale struct {
+	ID       string            `json:"-"`
+	Name     string            `json:"name"`   // shown in the language picker, in the language itself
+	Plural   string            `json:"plural"` // plural rule, see pluralRules; defaults to the ID
+	Messages map[string]string `json:"messages"`
+}
+
+var englishLocale = Locale{ID: "en", Name: "English", Plural: "en", Messages: englishMessages}
+
+// lang is the locale every string is currently looked up in.
+var lang = englishLocale
+
+// pluralRules map a count to its CLDR plural category for integers.
+var pluralRules = map[string]func(n int) string{
+	"en": func(n int) string {
+		if n == 1 {
+			return "one"
+		}
+		return "other"
+	},
+	"fr": func(n int) string {
+		if n == 0 || n == 1 {
+			return "one"
+		}
+		return "other"
+	},
+	"ru": func(n int) string {
+		if n < 0 {
+			n = -n
+		}
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return "one"
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return "few"
+		}
+		return "many"
+	},
+	"ja": func(n int) string { return "other" },
+}
+
+func (l Locale) plural(n int) string {
+	if rule, ok := pluralRules[l.Plural]; ok {
+		return rule(n)
+	}
+	return pluralRules["en"](n)
+}
+
+// lookup finds a message in the current locale, then in English. A missing
+// ID shows up as itself so it is easy to spot.
+func lookup(id string) string {
+	if s, ok := lang.Messages[id]; ok {
+		return s
+	}
+	if s, ok := englishLocale.Messages[id]; ok {
+		return s
+	}
+	return id
+}
+
+// tr returns the message for id, formatted with args if there are any.
+func tr(id string, args ...any) string {
+	if len(args) == 0 {
+		return lookup(id)
+	}
+	return fmt.Sprintf(lookup(id), args...)
+}
+
+// trn returns the plural form of id for n. Without args the message is
+// formatted with n alone.
+func trn(id string, n int, args ...any) string {
+	if len(args) == 0 {
+		args = []any{n}
+	}
+	for _, l := range []Locale{lang, englishLocale} {
+		if s, ok := l.Messages[id+"."+l.plural(n)]; ok {
+			return fmt.Sprintf(s, args...)
+		}
+		if s, ok := l.Messages[id+".other"]; ok {
+			return fmt.Sprintf(s, args...)
+		}
+	}
+	return id
+}
+
+// loadLocales returns English followed by every .json locale in dir,
+// bundled ones first and then any the player added. The file name is the
+// locale ID, e.g. ru.json.
+func loadLocales(dir string) []Locale {
+	locales := []Locale{englishLocale}
+	seen := map[string]bool{englishLocale.ID: true}
+
+	for _, fsys := range assetSources() {
+		entries, err := fs.ReadDir(fsys, dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			id := strings.TrimSuffix(e.Name(), ".json")
+			if e.IsDir() || path.Ext(e.Name()) != ".json" || seen[id] {
+				continue
+			}
+			l, err := loadLocale(fsys, path.Join(dir, e.Name()))
+			if err != nil {
+				log.Printf("locale %s: %v", e.Name(), err)
+				continue
+			}
+			seen[id] = true
+			locales = append(locales, l)
+		}
+	}
+	return locales
+}
+
+func loadLocale(fsys fs.FS, file string) (Locale, error) {
+	var l Locale
+	data, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return l, err
+	}
+	if err := json.Unmarshal(data, &l); err != nil {
+		return l, err
+	}
+
+	l.ID = strings.TrimSuffix(path.Base(file), ".json")
+	if l.Name == "" {
+		l.Name = l.ID
+	}
+	if l.Plural == "" {
+		l.Plural = l.ID
+	}
+	return l, nil
+}
+
+// applyLanguage switches to the language chosen in the settings, including
+// the window title.
+func (g *Game) applyLanguage() {
+	lang = g.locales[0]
+	for _, l := range g.locales {
+		if l.ID == g.settings.Language {
+			lang = l
+		}
+	}
+	ebiten.SetWindowTitle(tr("window.title"))
+}
+
+// cycleLanguage steps through the available languages.
+func (g *Game) cycleLanguage(delta int) {
+	ids := make([]string, len(g.locales))
+	for i, l := range g.locales {
+		ids[i] = l.ID
+	}
+	g.settings.Language = cycleString(ids, lang.ID, delta)
+	g.applyLanguage()
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPluralRules(t *testing.T) {
+	tests := map[string]map[int]string{
+		"en": {0: "other", 1: "one", 2: "other", 11: "other", 21: "other"},
+		"fr": {0: "one", 1: "one", 2: "other", 11: "other"},
+		"ru": {
+			0: "many", 1: "one", 2: "few", 4: "few", 5: "many", 11: "many", 12: "many",
+			14: "many", 21: "one", 22: "few", 25: "many", 101: "one", 111: "many", 112: "many", -3: "few",
+		},
+		"ja": {0: "other", 1: "other", 2: "other"},
+	}
+	for id, cases := range tests {
+		l := Locale{Plural: id}
+		for n, want := range cases {
+			if got := l.plural(n); got != want {
+				t.Errorf("%s: plural(%d) = %q, want %q", id, n, got, want)
+			}
+		}
+	}
+}
+
+func TestUnknownPluralRuleUsesEnglish(t *testing.T) {
+	l := Locale{Plural: "xx"}
+	if got := l.plural(1); got != "one" {
+		t.Errorf("plural(1) = %q, want \"one\"", got)
+	}
+}
+
+func TestCountedMessages(t *testing.T) {
+	defer func(saved Locale) { lang = saved }(lang)
+	lang = Locale{ID: "ru", Plural: "ru", Messages: map[string]string{
+		"test.games.one":  "%d игра",
+		"test.games.few":  "%d игры",
+		"test.games.many": "%d игр",
+	}}
+
+	for n, want := range map[int]string{1: "1 игра", 3: "3 игры", 5: "5 игр", 21: "21 игра"} {
+		if got := trn("test.games", n); got != want {
+			t.Errorf("trn(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestBundledLocales(t *testing.T) {
+	locales := loadLocales(localesDir)
+	ids := map[string]Locale{}
+	for _, l := range locales {
+		ids[l.ID] = l
+	}
+	ru, ok := ids["ru"]
+	if !ok {
+		t.Fatalf("bundled locales %v do not include ru", locales)
+	}
+
+	// Every English message is translated, in every plural form ru needs
+	for id := range englishMessages {
+		base, form := id, ""
+		if i := strings.LastIndex(id, "."); i >= 0 {
+			base, form = id[:i], id[i+1:]
+		}
+		if form == "one" || form == "other" {
+			for _, f := range []string{"one", "few", "many"} {
+				if _, ok := ru.Messages[base+"."+f]; !ok {
+					t.Errorf("ru is missing %s.%s", base, f)
+				}
+			}
+			continue
+		}
+		if _, ok := ru.Messages[id]; !ok {
+			t.Errorf("ru is missing %s", id)
+		}
+	}
+}
@@ -0,0 +1,366 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const (
+	leaderboardSize   = 20
+	maxNameLength     = 12
+	defaultPlayerName = "PLAYER"
+)
+
+// ==================== LEADERBOARD TYPES ====================
+
+type LeaderboardEntry struct {
+	Name     string    `json:"name"`
+	Score    int       `json:"score"`
+	Length   int       `json:"length"`
+	MaxCombo int       `json:"max_combo"`
+	Duration int64     `json:"duration_seconds"`
+	Date     time.Time `json:"date"`
+	Seed     int64     `json:"seed"`
+	Replay   *Replay   `json:"replay,omitempty"`
+}
+
+// BoardKey identifies one leaderboard: runs are only ranked against runs
+// played in the same mode, with the same rules, on the same arena size.
+type BoardKey struct {
+	Mode   string `json:"mode"`
+	Rules  string `json:"rules"`
+	ArenaW int    `json:"arena_w"`
+	ArenaH int    `json:"arena_h"`
+}
+
+// Leaderboards holds every board keyed by BoardKey.String(). They are shared
+// by all local profiles so everyone on the machine competes on the same tables.
+type Leaderboards map[string]*Leaderboard
+
+type Leaderboard struct {
+	Key     BoardKey           `json:"key"`
+	Entries []LeaderboardEntry `json:"entries"`
+}
+
+func (k BoardKey) String() string {
+	return fmt.Sprintf("%s/%s/%dx%d", k.Mode, k.Rules, k.ArenaW, k.ArenaH)
+}
+
+func (k BoardKey) Title() string {
+	return fmt.Sprintf("%s | %s | %dx%d", strings.ToUpper(modeName(k.Mode)), strings.ToUpper(k.Rules), k.ArenaW, k.ArenaH)
+}
+
+// ==================== LEADERBOARD LOGIC ====================
+
+func (g *Game) currentBoardKey() BoardKey {
+	return BoardKey{
+		Mode:   g.mode,
+		Rules:  g.rules.Name,
+		ArenaW: g.gridW,
+		ArenaH: g.gridH,
+	}
+}
+
+func (l *Leaderboards) board(key BoardKey) *Leaderboard {
+	if *l == nil {
+		*l = make(Leaderboards)
+	}
+	b, ok := (*l)[key.String()]
+	if !ok {
+		b = &Leaderboard{Key: key}
+		(*l)[key.String()] = b
+	}
+	return b
+}
+
+// boardNames returns the keys of all non-empty leaderboards in a stable order.
+func (l Leaderboards) boardNames() []string {
+	names := make([]string, 0, len(l))
+	for name, b := range l {
+		if len(b.Entries) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (b *Leaderboard) qualifies(score int) bool {
+	if score <= 0 {
+		return false
+	}
+	if len(b.Entries) < leaderboardSize {
+		return true
+	}
+	return score > b.Entries[len(b.Entries)-1].Score
+}
+
+// insert adds an entry and returns its 1-based rank, or 0 if it fell off the board.
+func (b *Leaderboard) insert(e LeaderboardEntry) int {
+	// After any equal scores, so earlier runs keep their place
+	i := sort.Search(len(b.Entries), func(i int) bool {
+		return b.Entries[i].Score < e.Score
+	})
+	if i >= leaderboardSize {
+		return 0
+	}
+	b.Entries = append(b.Entries, LeaderboardEntry{})
+	copy(b.Entries[i+1:], b.Entries[i:])
+	b.Entries[i] = e
+	if len(b.Entries) > leaderboardSize {
+		b.Entries = b.Entries[:leaderboardSize]
+	}
+	return i + 1
+}
+
+func (b *Leaderboard) rankFor(score int) int {
+	for i, e := range b.Entries {
+		if score > e.Score {
+			return i + 1
+		}
+	}
+	return len(b.Entries) + 1
+}
+
+// ==================== NAME ENTRY ====================
+
+// finishRun captures the final run details and opens the name prompt when the
+// score is good enough for the current leaderboard.
+func (g *Game) finishRun() {
+	g.runDuration = time.Since(g.gameStartTime)
+	g.lastRank = 0
+	g.enteringName = false
+	g.newUnlocks = g.checkUnlocks()
+	g.recordChallengeResult()
+
+	// Practice runs of an already attempted challenge are never ranked
+	if g.practice {
+		return
+	}
+	board := g.profiles.Leaderboards.board(g.currentBoardKey())
+	if board.qualifies(g.score) {
+		g.enteringName = true
+		g.nameBuffer = []rune(g.gameData.LastName)
+		if len(g.nameBuffer) == 0 {
+			g.nameBuffer = []rune(g.profile.Name)
+		}
+	}
+}
+
+// updateTextInput applies this frame's typed characters and backspace to buf.
+// It is shared by every name prompt in the game.
+func updateTextInput(buf []rune) []rune {
+	for _, r := range ebiten.AppendInputChars(nil) {
+		if len(buf) >= maxNameLength {
+			break
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == ' ' || r == '-' || r == '_' {
+			buf = append(buf, r)
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && len(buf) > 0 {
+		buf = buf[:len(buf)-1]
+	}
+	return buf
+}
+
+func (g *Game) updateNameEntry() {
+	g.nameBuffer = updateTextInput(g.nameBuffer)
+	// Controllers and touch screens cannot type, so confirm or a tap accepts
+	// the name as is
+	_, tapped := g.tapped()
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) || g.padPressed(ActionConfirm) || tapped {
+		g.submitLeaderboardEntry()
+	}
+}
+
+func (g *Game) submitLeaderboardEntry() {
+	name := strings.TrimSpace(string(g.nameBuffer))
+	if name == "" {
+		name = defaultPlayerName
+	}
+
+	board := g.profiles.Leaderboards.board(g.currentBoardKey())
+	g.lastRank = board.insert(LeaderboardEntry{
+		Name:     name,
+		Score:    g.score,
+		Length:   len(g.snake),
+		MaxCombo: g.maxCombo,
+		Duration: int64(g.runDuration.Seconds()),
+		Date:     time.Now(),
+		Seed:     g.seed,
+		Replay:   g.replay,
+	})
+	g.forgetVerification(g.currentBoardKey().String())
+	g.gameData.LastName = name
+	g.enteringName = false
+	g.saveGameData()
+}
+
+// ==================== LEADERBOARD SCREEN ====================
+
+func (g *Game) openLeaderboards() {
+	g.boardIndex = 0
+	current := g.currentBoardKey().String()
+	for i, name := range g.profiles.Leaderboards.boardNames() {
+		if name == current {
+			g.boardIndex = i
+		}
+	}
+	g.pushScene(&screenScene{update: g.updateLeaderboards, draw: g.drawLeaderboardScreen})
+}
+
+func (g *Game) updateLeaderboards() error {
+	if g.pressed(ActionLeft) {
+		g.switchBoard(-1)
+	}
+	if g.pressed(ActionRight) {
+		g.switchBoard(1)
+	}
+	if g.pressed(ActionConfirm) {
+		g.popScene()
+		return nil
+	}
+	g.updateUI(g.leaderboardView())
+	g.updateVerification()
+	return nil
+}
+
+func formatDuration(seconds int64) string {
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+// Leaderboard table columns, in pixels
+var boardColumns = []float64{35, 100, 60, 55, 50, 50, 85, 45}
+
+// boardRow lays out one table row, numbers right-aligned.
+func boardRow(cells []string, c color.Color) Widget {
+	row := &Row{Widths: boardColumns, Spacing: 8}
+	for i, s := range cells {
+		align := AnchorLeft
+		if i >= 2 && i <= 5 {
+			align = AnchorRight
+		}
+		row.Cells = append(row.Cells, &Label{Text: s, Color: c, Align: align})
+	}
+	return row
+}
+
+func (g *Game) leaderboardView() Widget {
+	children := []Widget{label(tr("boards.title"), palette.UI.Title), Gap(20)}
+
+	names := g.profiles.Leaderboards.boardNames()
+	if len(names) == 0 {
+		children = append(children, label(tr("boards.empty"), palette.UI.Text))
+	} else {
+		if g.boardIndex >= len(names) {
+			g.boardIndex = 0
+		}
+		board := g.profiles.Leaderboards[names[g.boardIndex]]
+
+		boardTitle := fmt.Sprintf("<  %s  (%d/%d)  >", board.Key.Title(), g.boardIndex+1, len(names))
+		children = append(children,
+			&Label{Text: boardTitle, Color: palette.UI.Accent, OnTap: g.switchBoard},
+			Gap(12),
+			boardRow([]string{"#", tr("boards.name"), tr("boards.score"), tr("boards.length"), tr("boards.combo"), tr("boards.time"), tr("boards.date"), tr("boards.check")}, palette.UI.Heading),
+		)
+
+		// Re-simulate every run so hand-edited scores are flagged. The
+		// checks run a slice per tick and fill in as they finish
+		results, verified := g.verifyBoard(names[g.boardIndex])
+		flagged, unverified, skipped := 0, 0, 0
+
+		highlight := g.lastRank > 0 && names[g.boardIndex] == g.currentBoardKey().String()
+		for i, e := range board.Entries {
+			check := tr("boards.ok")
+			switch results[i].Status {
+			case verifyNoReplay:
+				check = tr("boards.no_replay")
+				unverified++
+			case verifyMismatch:
+				check = tr("boards.fail")
+				flagged++
+			case verifyInvalid:
+				check = tr("boards.invalid")
+				flagged++
+			case verifySkipped:
+				check = tr("boards.skipped")
+				skipped++
+			case verifyPending:
+				check = tr("boards.pending")
+			}
+
+			rowColor := palette.UI.Text
+			if i == 0 {
+				rowColor = palette.UI.Highlight
+			}
+			if highlight && i+1 == g.lastRank {
+				rowColor = palette.UI.Selected
+			}
+			if results[i].Status.failed() {
+				rowColor = palette.UI.Danger
+			}
+			children = append(children, boardRow([]string{
+				fmt.Sprintf("%d.", i+1), e.Name, fmt.Sprint(e.Score), fmt.Sprint(e.Length), fmt.Sprint(e.MaxCombo),
+				formatDuration(e.Duration), e.Date.Format("2006-01-02"), check,
+			}, rowColor))
+		}
+
+		if flagged > 0 {
+			warning := trn("boards.flagged", flagged)
+			children = append(children, Gap(8), label(warning, palette.UI.Danger))
+		}
+		if unverified > 0 {
+			children = append(children, Gap(8), label(trn("boards.unverified", unverified), palette.UI.Danger))
+		}
+		if skipped > 0 {
+			children = append(children, Gap(8), label(trn("boards.too_long", skipped), palette.UI.Danger))
+		}
+		if !verified {
+			children = append(children, Gap(8), label(tr("boards.verifying"), palette.UI.Accent))
+		}
+	}
+
+	children = append(children, Gap(20), label(tr("boards.hint"), palette.UI.Item))
+	return &Screen{
+		Backdrop: palette.UI.Overlay,
+		Children: []Widget{&Panel{Anchor: AnchorCenter, Spacing: 7, Children: children}},
+	}
+}
+
+// switchBoard steps through the boards; tapping the title's left or right
+// half does the same.
+func (g *Game) switchBoard(delta int) {
+	names := g.profiles.Leaderboards.boardNames()
+	if len(names) > 0 {
+		g.boardIndex = (g.boardIndex + delta + len(names)) % len(names)
+	}
+}
+
+func (g *Game) drawLeaderboardScreen(screen *ebiten.Image) {
+	g.renderUI(screen, g.leaderboardView())
+}
+
+// nameEntryView is the leaderboard name prompt shown on the game-over screen.
+func (g *Game) nameEntryView() []Widget {
+	board := g.profiles.Leaderboards.board(g.currentBoardKey())
+
+	cursor := " "
+	if time.Now().UnixMilli()/500%2 == 0 {
+		cursor = "_"
+	}
+	return []Widget{
+		label(tr("boards.enter_name", board.rankFor(g.score)), palette.UI.Highlight),
+		Gap(6),
+		label("[ "+string(g.nameBuffer)+cursor+" ]", color.White),
+		Gap(6),
+		label(tr("boards.name_hint"), palette.UI.Text),
+	}
+}
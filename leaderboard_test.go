@@ -0,0 +1,30 @@
+package main
+
+import "testing"
+
+func TestLeaderboardInsertRank(t *testing.T) {
+	var b Leaderboard
+	for _, score := range []int{50, 80, 50, 20} {
+		b.insert(LeaderboardEntry{Score: score})
+	}
+
+	// Identical entries are told apart by position, and ties go after
+	if rank := b.insert(LeaderboardEntry{Score: 50}); rank != 4 {
+		t.Errorf("tied entry ranked %d, want 4", rank)
+	}
+	for i := 1; i < len(b.Entries); i++ {
+		if b.Entries[i-1].Score < b.Entries[i].Score {
+			t.Fatalf("board out of order: %v", b.Entries)
+		}
+	}
+
+	for len(b.Entries) < leaderboardSize {
+		b.insert(LeaderboardEntry{Score: 100})
+	}
+	if rank := b.insert(LeaderboardEntry{Score: 1}); rank != 0 {
+		t.Errorf("entry below a full board ranked %d, want 0", rank)
+	}
+	if len(b.Entries) != leaderboardSize {
+		t.Errorf("board has %d entries, want %d", len(b.Entries), leaderboardSize)
+	}
+}
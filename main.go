@@ -3,7 +3,6 @@ package main
 import (
 	"bytes"
 	"encoding/json"
-	"fmt"
 	"image/color"
 	"log"
 	"math"
@@ -13,11 +12,7 @@ import (
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/audio"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
-	"github.com/hajimehoshi/ebiten/v2/text"
-
-	"golang.org/x/image/font/basicfont"
 )
 
 const (
@@ -28,6 +23,11 @@ const (
 	maxSpeed     = 20
 	sampleRate   = 44100
 	saveFile     = "snake_enhanced.json"
+	
+	// Background animation
+	meteorSpawnRate     = 1.2 // chance per second of a new meteor
+	meteorTrailInterval = 1.0 / 60 // seconds between meteor trail points
+	maxBackgroundStep   = 0.1 // longest step, in seconds, after a stall
 )
 
 // ==================== TYPES ====================
@@ -35,17 +35,6 @@ const (
 type Point struct{ X, Y int }
 type Vector2 struct{ X, Y float64 }
 
-type Particle struct {
-	pos      Vector2
-	vel      Vector2
-	life     float64
-	maxLife  float64
-	color    color.RGBA
-	size     float64
-	rotation float64
-	rotVel   float64
-}
-
 type Meteor struct {
 	pos    Vector2
 	vel    Vector2
@@ -54,15 +43,15 @@ type Meteor struct {
 	trail  []Vector2
 	life   float64
 	glow   float64
+	trailTimer float64 // seconds since the last trail point
 }
 
 type PowerUp struct {
-	pos      Point
-	type_    int // 0: bonus points, 1: speed boost, 2: invulnerability
-	timer    int
-	active   bool
-	pulse    float64
-	sparkles []Particle
+	pos    Point
+	type_  int // 0: bonus points, 1: speed boost, 2: invulnerability
+	timer  int
+	active bool
+	pulse  float64
 }
 
 type GameData struct {
@@ -71,24 +60,22 @@ type GameData struct {
 	TotalScore   int   `json:"total_score"`
 	BestCombo    int   `json:"best_combo"`
 	PlayTime     int64 `json:"play_time_seconds"`
+	LastName     string `json:"last_name"`
 }
 
-type GameState int
-
-const (
-	StateTitleScreen GameState = iota
-	StateMenu
-	StatePlaying
-	StatePaused
-	StateGameOver
-)
-
 type Renderer struct {
 	game           *Game
 	backgroundGrid [][]BackgroundCell
-	starField      []Star
 	meteors        []Meteor
-	time           float64
+	time           float64   // seconds of background animation
+	lastUpdate     time.Time // when update last ran, for real elapsed time
+	batch          quadBatch // shared by the background, grid, item, snake and particle layers
+
+	// Procedural space background, see background.go
+	bg, prevBg     *backgroundVariant // current variant and the one fading out
+	bgFade         float64            // 0..1 through the cross-fade
+	parallax       Vector2            // how far the nearest layer has drifted
+	parallaxVel    Vector2
 }
 
 type BackgroundCell struct {
@@ -113,23 +100,73 @@ type Game struct {
 	grow           int
 	food           Point
 	powerUp        PowerUp
-	particles      []Particle
-	rng            *rand.Rand
+	particles      []Particle     // pooled, see particles.go
+	sparkles       particleStream // around the active power-up
+	rng            *rand.Rand // gameplay only, seeded per run so replays reproduce
+	fxRng          *rand.Rand // visual effects, never affects the simulation
 	frame          int
 	speed          int
 	baseSpeed      int
 	score          int
-	gameData       GameData
+	gameData       *GameData
+	profile        *Profile
+	profiles       ProfileStore
 	combo          int
 	maxCombo       int
 	comboTimer     int
 	gameStartTime  time.Time
+	seed           int64
+	replay         *Replay
+	mode           string
+	rules          Rules
+	challenge      *Challenge
+	practice       bool
+	fixedArena     Point
+	awaitingWindow bool // auto arena picked before the first Layout, see chooseArena
+	selectedMode   string
+	endReason      string
+
+	// Mode-specific state
+	foodActive     bool
+	foodTimer      int
+	hunger         int
+	foodEaten      int
+	peakLength     int
+
+	// Difficulty progression
+	difficulty     Difficulty
+	level          int
+	levelUpTimer   int
+	hazards        []Point
+	runDuration    time.Duration
 
 	// Game state management
-	state         GameState
+	scenes        []Scene
+	sceneFade     int
+	over          bool // the run has ended
 	menuOption    int
 	isFullscreen  bool
 
+	// Leaderboards
+	enteringName  bool
+	nameBuffer    []rune
+	lastRank      int
+	boardIndex    int
+	verifyCache   map[string][]VerifyResult
+	verifying     *boardCheck // board whose replays are being simulated
+	newUnlocks    []string
+
+	// Menus
+	challengeCursor int
+	modeCursor      int
+
+	// Profiles
+	profileCursor        int
+	profileTarget        int // profile Rename and Delete act on
+	profileConfirmCursor int
+	profileEdit          profileEditMode
+	profileMessage       string
+
 	// Visual effects
 	foodPulse      float64
 	scaleFactor    float64
@@ -138,12 +175,41 @@ type Game struct {
 	gridW          int
 	gridH          int
 	cellSize       int
+	camera         Vector2 // arena cell at the centre of the view, see arena.go
 	speedBoostTime int
 	slowMotionTime int
 	invulnerable   int
-	shakeIntensity float64
+	shakeIntensity float64 // camera shake, applied by the post-processing pipeline
+	hitFlash       float64 // 0..1, see flashHit
+	post           postPipeline
+	postFXCursor   int
 	trailOpacity   []float64
+	prevSnake      []Point
+	lastMoveFrame  int
+	snakeLayer     *ebiten.Image
 	headPulse      float64
+	skins          []*Skin
+	accessCursor   int
+	optionsCursor  int
+	settings       Settings
+	themes         []Theme
+	locales        []Locale
+
+	// Binding editor
+	controlsCursor  int
+	editingControls bool // the binding editor is open, see handleGlobalInput
+	controlsSlot    int
+	capturing       bool
+	controlsMessage string
+
+	// Gamepads
+	pads          []*gamepad
+	padCursor     int
+	padMessage    string
+	padToastTimer int
+
+	// Mouse and touch
+	pointer pointerState
 
 	// Audio system
 	audioCtx       *audio.Context
@@ -158,48 +224,29 @@ type Game struct {
 	renderer *Renderer
 }
 
-// ==================== GREEN/BLACK/RED COLOR PALETTE ====================
-
-var (
-	// Base colors - Green/Black/Red theme
-	bgColor     = color.RGBA{5, 10, 5, 255}         // Deep black-green
-	gridColor   = color.RGBA{20, 40, 20, 40}        // Subtle dark green grid
-	headColor   = color.RGBA{0, 255, 50, 255}       // Bright lime green
-	bodyColor   = color.RGBA{0, 180, 30, 255}       // Forest green
-	foodColor   = color.RGBA{255, 50, 50, 255}      // Bright red (highly visible)
-	bonusColor  = color.RGBA{255, 255, 100, 255}    // Bright yellow
-	shadowColor = color.RGBA{0, 0, 0, 120}
-
-	// Meteor colors - red/orange theme
-	meteorColors = []color.RGBA{
-		{255, 100, 50, 255},  // Red-orange
-		{255, 150, 100, 255}, // Orange
-		{255, 200, 150, 255}, // Light orange
-		{200, 50, 50, 255},   // Dark red
-	}
-
-	// Star colors - green theme with some variety
-	starColors = []color.RGBA{
-		{200, 255, 200, 255}, // Light green
-		{150, 255, 150, 255}, // Medium green
-		{100, 200, 100, 255}, // Forest green
-		{255, 255, 255, 255}, // White for variety
-	}
-)
-
 // ==================== INITIALIZATION ====================
 
 func NewGame() *Game {
 	g := &Game{
 		rng:        rand.New(rand.NewSource(time.Now().UnixNano())),
+		fxRng:      rand.New(rand.NewSource(time.Now().UnixNano())),
 		menuOption: 0,
-		state:      StateTitleScreen,
 	}
 	
+	g.settings = loadSettings()
+	g.skins = loadSkins(skinsDir)
+	g.themes = loadThemes(themesDir)
+	g.locales = loadLocales(localesDir)
+	g.applyLanguage()
 	g.loadGameData()
 	g.initializeAudio()
+	g.applyAudioSettings()
 	g.initializeRenderer()
-	g.resetGameplay()
+	
+	// Start on the title screen so the player picks a profile before the
+	// first run
+	g.selectedMode = modeEndless
+	g.setScene(&titleScene{})
 	
 	return g
 }
@@ -218,36 +265,17 @@ func (g *Game) initializeRenderer() {
 	g.renderer.initializeBackground()
 }
 
-func (g *Game) calculatePlayfieldDimensions() {
-	// Calculate optimal grid size based on screen dimensions
-	maxCellsW := g.screenWidth / 15  // Minimum cell size of 15 pixels
-	maxCellsH := g.screenHeight / 15
-	
-	// Use aspect ratio to maintain good gameplay
-	aspectRatio := float64(g.screenWidth) / float64(g.screenHeight)
-	
-	if aspectRatio > 1.5 { // Wide screen
-		g.gridW = int(math.Min(float64(maxCellsW), 50))
-		g.gridH = int(float64(g.gridW) / aspectRatio)
-	} else { // Standard or tall screen
-		g.gridH = int(math.Min(float64(maxCellsH), 40))
-		g.gridW = int(float64(g.gridH) * aspectRatio)
+// ==================== AUDIO SYSTEM ====================
+
+// playSound restarts a sound effect. Players are nil in headless simulations.
+func (g *Game) playSound(p *audio.Player) {
+	if p == nil {
+		return
 	}
-	
-	// Ensure minimum playfield size
-	if g.gridW < 20 { g.gridW = 20 }
-	if g.gridH < 15 { g.gridH = 15 }
-	
-	// Calculate cell size that fits the screen perfectly
-	cellSizeW := g.screenWidth / g.gridW
-	cellSizeH := g.screenHeight / g.gridH
-	g.cellSize = int(math.Min(float64(cellSizeW), float64(cellSizeH)))
-	
-	g.scaleFactor = float64(g.cellSize) / float64(baseCellSize)
+	p.Rewind()
+	p.Play()
 }
 
-// ==================== AUDIO SYSTEM ====================
-
 func newBeepPlayer(ctx *audio.Context, freq float64, durSec float64) *audio.Player {
 	n := int(float64(sampleRate) * durSec)
 	buf := make([]byte, n*4)
@@ -323,112 +351,105 @@ func (r *Renderer) initializeBackground() {
 		r.backgroundGrid[x] = make([]BackgroundCell, baseGridH*2)
 		for y := range r.backgroundGrid[x] {
 			r.backgroundGrid[x][y] = BackgroundCell{
-				intensity:  r.game.rng.Float64() * 0.3, // Reduced for better contrast
-				phase:      r.game.rng.Float64() * 2 * math.Pi,
-				colorShift: r.game.rng.Float64() * 2 * math.Pi,
+				intensity:  r.game.fxRng.Float64() * 0.3, // Reduced for better contrast
+				phase:      r.game.fxRng.Float64() * 2 * math.Pi,
+				colorShift: r.game.fxRng.Float64() * 2 * math.Pi,
 			}
 		}
 	}
 	
-	// Initialize star field
-	starCount := 100 // Reduced for cleaner look
-	r.starField = make([]Star, starCount)
-	for i := range r.starField {
-		r.starField[i] = Star{
-			pos: Vector2{
-				X: r.game.rng.Float64() * 1920,
-				Y: r.game.rng.Float64() * 1080,
-			},
-			brightness: 0.4 + r.game.rng.Float64()*0.6,
-			twinkle:    r.game.rng.Float64() * 2 * math.Pi,
-			speed:      0.1 + r.game.rng.Float64()*0.2,
-			size:       1 + r.game.rng.Float64()*1.5,
-		}
-	}
-	
 	// Initialize meteors
 	meteorCount := 12
 	r.meteors = make([]Meteor, 0, meteorCount)
 }
 
-func (r *Renderer) drawSpaceBackground(screen *ebiten.Image) {
-	r.time += 0.016 // Assuming 60 FPS
-	
-	// Fill with deep space color
-	screen.Fill(bgColor)
-	
-	// Draw stars with twinkling effect
-	r.drawStarField(screen)
-	
-	// Draw meteors
-	r.drawMeteors(screen)
-	
-	// Draw animated grid overlay (more subtle)
-	r.drawAnimatedGrid(screen)
-}
-
-func (r *Renderer) drawStarField(screen *ebiten.Image) {
-	for i := range r.starField {
-		star := &r.starField[i]
-		
-		// Update twinkle effect
-		star.twinkle += star.speed * 0.1
-		twinkleFactor := 0.7 + 0.3*math.Sin(star.twinkle)
-		
-		// Calculate final brightness and size
-		finalBrightness := star.brightness * twinkleFactor
-		finalSize := star.size * (0.8 + 0.4*twinkleFactor)
-		
-		// Choose star color - mostly green theme
-		starColor := starColors[i%len(starColors)]
-		alpha := uint8(float64(starColor.A) * finalBrightness)
-		finalColor := color.RGBA{starColor.R, starColor.G, starColor.B, alpha}
-		
-		// Draw star
-		ebitenutil.DrawRect(screen,
-			star.pos.X - finalSize/2,
-			star.pos.Y - finalSize/2,
-			finalSize, finalSize, finalColor)
+// update advances every background animation by the real time since the
+// last call, so it runs at the same speed whatever the refresh rate or tick
+// rate and carries on behind pauses and menus. Reduced motion holds it still.
+func (r *Renderer) update() {
+	now := time.Now()
+	dt := now.Sub(r.lastUpdate).Seconds()
+	r.lastUpdate = now
+	if dt > maxBackgroundStep { // first call, or the window was stalled
+		dt = maxBackgroundStep
+	}
+	still := r.game.access().ReducedMotion
+	r.updateBackground(dt, still)
+	if still {
+		return
 	}
+	
+	r.time += dt
+	r.updateMeteors(dt)
 }
 
-func (r *Renderer) drawMeteors(screen *ebiten.Image) {
+func (r *Renderer) updateMeteors(dt float64) {
+	// Speeds were tuned per 60Hz frame
+	frames := dt * 60
+	
 	// Spawn new meteors occasionally
-	if r.game.rng.Float64() < 0.02 && len(r.meteors) < 15 {
+	maxMeteors := int(15 * r.game.effectsScale())
+	if r.game.fxRng.Float64() < meteorSpawnRate*dt && len(r.meteors) < maxMeteors {
 		meteor := Meteor{
 			pos: Vector2{
-				X: -50 + r.game.rng.Float64()*100,
-				Y: -50 + r.game.rng.Float64()*100,
+				X: -50 + r.game.fxRng.Float64()*100,
+				Y: -50 + r.game.fxRng.Float64()*100,
 			},
 			vel: Vector2{
-				X: 2 + r.game.rng.Float64()*4,
-				Y: 3 + r.game.rng.Float64()*5,
+				X: 2 + r.game.fxRng.Float64()*4,
+				Y: 3 + r.game.fxRng.Float64()*5,
 			},
-			size:  3 + r.game.rng.Float64()*8,
-			color: meteorColors[r.game.rng.Intn(len(meteorColors))],
+			size:  3 + r.game.fxRng.Float64()*8,
+			color: palette.Meteors[r.game.fxRng.Intn(len(palette.Meteors))].rgba(),
 			trail: make([]Vector2, 0, 20),
 			life:  1.0,
-			glow:  r.game.rng.Float64(),
+			glow:  r.game.fxRng.Float64(),
 		}
 		r.meteors = append(r.meteors, meteor)
 	}
 	
-	// Update and draw meteors
 	for i := len(r.meteors) - 1; i >= 0; i-- {
 		meteor := &r.meteors[i]
 		
 		// Update position
-		meteor.pos.X += meteor.vel.X
-		meteor.pos.Y += meteor.vel.Y
+		meteor.pos.X += meteor.vel.X * frames
+		meteor.pos.Y += meteor.vel.Y * frames
 		
-		// Add to trail
-		meteor.trail = append(meteor.trail, meteor.pos)
-		if len(meteor.trail) > 15 {
-			meteor.trail = meteor.trail[1:]
+		// Add to trail at a steady rate so its length does not depend on the tick rate
+		meteor.trailTimer += dt
+		for meteor.trailTimer >= meteorTrailInterval {
+			meteor.trailTimer -= meteorTrailInterval
+			meteor.trail = append(meteor.trail, meteor.pos)
+			if len(meteor.trail) > 15 {
+				meteor.trail = meteor.trail[1:]
+			}
 		}
 		
 		// Update glow
-		meteor.glow += 0.1
+		meteor.glow += 0.1 * frames
+		
+		// Remove meteors that are off screen
+		if meteor.pos.X > float64(r.game.screenWidth)+100 || 
+		   meteor.pos.Y > float64(r.game.screenHeight)+100 {
+			r.meteors = append(r.meteors[:i], r.meteors[i+1:]...)
+		}
+	}
+}
+
+func (r *Renderer) drawSpaceBackground(screen *ebiten.Image) {
+	// Fill with deep space color
+	screen.Fill(palette.Background)
+	
+	// Nebula, parallax stars and planets
+	r.drawBackdrop(screen)
+	
+	// Draw meteors
+	r.drawMeteors(screen)
+}
+
+func (r *Renderer) drawMeteors(screen *ebiten.Image) {
+	for i := range r.meteors {
+		meteor := &r.meteors[i]
 		glowFactor := 0.7 + 0.3*math.Sin(meteor.glow)
 		
 		// Draw trail
@@ -443,7 +464,7 @@ func (r *Renderer) drawMeteors(screen *ebiten.Image) {
 			}
 			
 			if trailSize > 0.5 {
-				ebitenutil.DrawRect(screen,
+				r.batch.rect(screen,
 					trailPos.X - trailSize/2,
 					trailPos.Y - trailSize/2,
 					trailSize, trailSize, trailColor)
@@ -461,23 +482,18 @@ func (r *Renderer) drawMeteors(screen *ebiten.Image) {
 			meteor.color.B,
 			uint8(float64(meteor.color.A) * 0.3),
 		}
-		ebitenutil.DrawRect(screen,
+		r.batch.rect(screen,
 			meteor.pos.X - glowSize/2,
 			meteor.pos.Y - glowSize/2,
 			glowSize, glowSize, glowColor)
 		
 		// Core
-		ebitenutil.DrawRect(screen,
+		r.batch.rect(screen,
 			meteor.pos.X - coreSize/2,
 			meteor.pos.Y - coreSize/2,
 			coreSize, coreSize, meteor.color)
-		
-		// Remove meteors that are off screen
-		if meteor.pos.X > float64(r.game.screenWidth)+100 || 
-		   meteor.pos.Y > float64(r.game.screenHeight)+100 {
-			r.meteors = append(r.meteors[:i], r.meteors[i+1:]...)
-		}
 	}
+	r.batch.flush(screen)
 }
 
 func (r *Renderer) drawAnimatedGrid(screen *ebiten.Image) {
@@ -485,18 +501,15 @@ func (r *Renderer) drawAnimatedGrid(screen *ebiten.Image) {
 		return
 	}
 	
-	// Only draw grid during gameplay
-	if r.game.state != StatePlaying && r.game.state != StatePaused {
-		return
-	}
-	
 	// Calculate grid offset to center the playfield
-	offsetX := (r.game.screenWidth - r.game.gridW*r.game.cellSize) / 2
-	offsetY := (r.game.screenHeight - r.game.gridH*r.game.cellSize) / 2
+	originX, originY := r.game.arenaOrigin()
+	offsetX, offsetY := int(originX), int(originY)
 	
-	// Draw animated background cells within the playfield (very subtle)
-	for x := 0; x < r.game.gridW; x++ {
-		for y := 0; y < r.game.gridH; y++ {
+	// Draw animated background cells within the playfield (very subtle),
+	// skipping the parts of a scrolling arena that are off screen
+	x0, y0, x1, y1 := r.game.visibleCells()
+	for x := x0; x < x1; x++ {
+		for y := y0; y < y1; y++ {
 			// Use modulo to wrap background pattern
 			bgX := x % len(r.backgroundGrid)
 			bgY := y % len(r.backgroundGrid[0])
@@ -509,11 +522,11 @@ func (r *Renderer) drawAnimatedGrid(screen *ebiten.Image) {
 			if intensity < 0 { intensity = 0 }
 			if intensity > 0.3 { intensity = 0.3 } // Very subtle
 			
-			// Green-tinted background cells
-			baseIntensity := int(intensity * 255)
-			red := uint8(float64(baseIntensity) * 0.3)
-			green := uint8(baseIntensity)
-			blue := uint8(float64(baseIntensity) * 0.3)
+			// Background cells tinted by the theme
+			tint := palette.BackgroundTint
+			red := uint8(intensity * float64(tint.R))
+			green := uint8(intensity * float64(tint.G))
+			blue := uint8(intensity * float64(tint.B))
 
 			
 			cellColor := color.RGBA{red, green, blue, 30}
@@ -522,31 +535,62 @@ func (r *Renderer) drawAnimatedGrid(screen *ebiten.Image) {
 			cellY := float64(offsetY + y*r.game.cellSize)
 			cellSize := float64(r.game.cellSize)
 			
-			ebitenutil.DrawRect(screen, cellX, cellY, cellSize, cellSize, cellColor)
+			r.batch.rect(screen, cellX, cellY, cellSize, cellSize, cellColor)
 		}
 	}
 	
 	// Draw grid lines with subtle green glow
 	gridAlpha := uint8(40 + 10*math.Sin(r.time*0.3))
-	gridColor := color.RGBA{30, 60, 30, gridAlpha}
+	gridColor := color.RGBA{palette.Grid.R, palette.Grid.G, palette.Grid.B, gridAlpha}
 	
 	// Vertical lines
-	for x := 0; x <= r.game.gridW; x++ {
+	for x := x0; x <= x1; x++ {
 		lineX := float64(offsetX + x*r.game.cellSize)
-		ebitenutil.DrawRect(screen, lineX-0.5, float64(offsetY), 1, float64(r.game.gridH*r.game.cellSize), gridColor)
+		r.batch.rect(screen, lineX-0.5, float64(offsetY + y0*r.game.cellSize), 1, float64((y1-y0)*r.game.cellSize), gridColor)
 	}
 	
 	// Horizontal lines
-	for y := 0; y <= r.game.gridH; y++ {
+	for y := y0; y <= y1; y++ {
 		lineY := float64(offsetY + y*r.game.cellSize)
-		ebitenutil.DrawRect(screen, float64(offsetX), lineY-0.5, float64(r.game.gridW*r.game.cellSize), 1, gridColor)
+		r.batch.rect(screen, float64(offsetX + x0*r.game.cellSize), lineY-0.5, float64((x1-x0)*r.game.cellSize), 1, gridColor)
 	}
+	r.batch.flush(screen)
 }
 
 // ==================== GAME STATE MANAGEMENT ====================
 
 func (g *Game) resetGameplay() {
-	g.calculatePlayfieldDimensions()
+	// Close the previous run's scenes before its stats are overwritten
+	g.setScene(&playScene{})
+	g.challenge = nil
+	g.practice = false
+	if g.selectedMode == "" {
+		g.selectedMode = modeEndless
+	}
+	g.mode = g.selectedMode
+	p := g.playerSettings()
+	g.rules = composeRules(p.Rules, difficultyByID(g.profile.Difficulty), p.StartSpeed)
+	g.fixedArena = arenaPresetByID(p.Arena).Size
+	g.chooseArena()
+	
+	// Every run gets its own seed so leaderboard entries can be replayed
+	g.startRun(time.Now().UnixNano())
+	g.startMusic()
+}
+
+func (g *Game) startMusic() {
+	if g.bgPlayer != nil {
+		g.bgPlayer.Rewind()
+		g.bgPlayer.Play()
+	}
+}
+
+// startRun resets the simulation for the current arena size. It never touches
+// the screen or audio so it can also drive headless replay verification.
+func (g *Game) startRun(seed int64) {
+	g.seed = seed
+	g.rng = rand.New(rand.NewSource(seed))
+	g.replay = &Replay{}
 	
 	midX, midY := g.gridW/2, g.gridH/2
 	g.snake = []Point{{midX, midY}, {midX-1, midY}, {midX-2, midY}}
@@ -558,36 +602,59 @@ func (g *Game) resetGameplay() {
 	g.combo = 0
 	g.maxCombo = 0
 	g.comboTimer = 0
-	g.state = StatePlaying
+	g.over = false
 	g.foodPulse = 0
 	g.headPulse = 0
 	g.speedBoostTime = 0
 	g.slowMotionTime = 0
 	g.invulnerable = 0
 	g.shakeIntensity = 0
-	g.speed = g.baseSpeed
+	g.hitFlash = 0
 	g.particles = g.particles[:0]
 	g.trailOpacity = make([]float64, len(g.snake))
+	g.prevSnake = g.prevSnake[:0]
+	g.lastMoveFrame = 0
 	g.powerUp = PowerUp{}
 	g.gameStartTime = time.Now()
-	g.baseSpeed = 10
+	g.baseSpeed = g.rules.StartSpeed
+	g.speed = g.baseSpeed
+	g.difficulty = difficultyByID(g.rules.Difficulty)
+	g.level = 1
+	g.levelUpTimer = 0
+	g.hazards = nil
+	g.enteringName = false
+	g.lastRank = 0
+	g.endReason = ""
+	g.hunger = 0
+	g.foodTimer = 0
+	g.foodEaten = 0
+	g.peakLength = len(g.snake)
 	
 	g.placeFood()
-	g.bgPlayer.Rewind()
-	g.bgPlayer.Play()
+	g.resetCamera()
 }
 
 func (g *Game) loadGameData() {
-	data, err := os.ReadFile(saveFile)
-	if err != nil {
-		g.gameData = GameData{}
-		return
+	g.loadGameDataFrom(saveFile)
+	g.migratePlayerSettings()
+}
+
+func (g *Game) loadGameDataFrom(path string) {
+	g.profiles = ProfileStore{}
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &g.profiles)
+		if len(g.profiles.Profiles) == 0 {
+			g.profiles.migrateLegacy(data)
+		}
+	}
+	if len(g.profiles.Profiles) == 0 {
+		g.profiles.Profiles = []*Profile{{Name: defaultProfileName}}
 	}
-	json.Unmarshal(data, &g.gameData)
+	g.selectProfile(g.profiles.Active)
 }
 
 func (g *Game) saveGameData() {
-	data, _ := json.Marshal(g.gameData)
+	data, _ := json.Marshal(g.profiles)
 	os.WriteFile(saveFile, data, 0644)
 }
 
@@ -603,15 +670,16 @@ func (g *Game) placeFood() {
 				break
 			}
 		}
-		if !occupied && (g.powerUp.pos != f || !g.powerUp.active) {
+		if !occupied && !g.isHazard(f) && (g.powerUp.pos != f || !g.powerUp.active) {
 			g.food = f
+			g.foodActive = true
 			return
 		}
 	}
 }
 
 func (g *Game) placePowerUp() {
-	if g.powerUp.active || g.rng.Float64() > 0.15 {
+	if g.powerUp.active || g.rng.Float64() > g.powerUpChance() {
 		return
 	}
 	
@@ -627,191 +695,191 @@ func (g *Game) placePowerUp() {
 				break
 			}
 		}
-		if !occupied {
+		if !occupied && !g.isHazard(p) {
 			g.powerUp = PowerUp{
 				pos:    p,
-				type_:  g.rng.Intn(3),
+				type_:  g.rules.pickPowerUp(g.rng.Intn),
 				timer:  600, // 10 seconds at 60fps
 				active: true,
 				pulse:  0,
-				sparkles: make([]Particle, 0, 10),
 			}
 			return
 		}
 	}
 }
 
-func (g *Game) addParticles(pos Point, count int, particleColor color.RGBA) {
-	// Calculate screen position considering playfield offset
-	offsetX := (g.screenWidth - g.gridW*g.cellSize) / 2
-	offsetY := (g.screenHeight - g.gridH*g.cellSize) / 2
-	
-	screenX := float64(offsetX + pos.X*g.cellSize + g.cellSize/2)
-	screenY := float64(offsetY + pos.Y*g.cellSize + g.cellSize/2)
-	
-	for i := 0; i < count; i++ {
-		angle := float64(i) * 2 * math.Pi / float64(count) + g.rng.Float64()*0.5
-		speed := 2.0 + g.rng.Float64()*4.0
-		g.particles = append(g.particles, Particle{
-			pos:      Vector2{screenX, screenY},
-			vel:      Vector2{math.Cos(angle) * speed, math.Sin(angle) * speed},
-			life:     1.0,
-			maxLife:  0.8 + g.rng.Float64()*0.4,
-			color:    particleColor,
-			size:     2.0 + g.rng.Float64()*3.0,
-			rotation: g.rng.Float64() * 2 * math.Pi,
-			rotVel:   (g.rng.Float64() - 0.5) * 0.3,
-		})
-	}
-}
-
-func (g *Game) updateParticles() {
-	for i := len(g.particles) - 1; i >= 0; i-- {
-		p := &g.particles[i]
-		p.pos.X += p.vel.X
-		p.pos.Y += p.vel.Y
-		p.vel.X *= 0.98
-		p.vel.Y *= 0.98
-		p.rotation += p.rotVel
-		p.life -= 1.0 / 60.0 / p.maxLife
-		p.size *= 0.99
-		
-		if p.life <= 0 || p.size < 0.5 {
-			g.particles = append(g.particles[:i], g.particles[i+1:]...)
-		}
-	}
-}
-
 // ==================== MAIN UPDATE FUNCTION ====================
 
 func (g *Game) Update() error {
+	g.renderer.update()
+	g.updatePostFX()
+	g.updateGamepads()
+	g.updatePointer()
 	g.handleGlobalInput()
-	
-	switch g.state {
-	case StateTitleScreen:
-		return g.updateTitleScreen()
-	case StateMenu:
-		return g.updateMenu()
-	case StatePlaying:
-		return g.updateGameplay()
-	case StatePaused:
-		return g.updatePaused()
-	case StateGameOver:
-		return g.updateGameOver()
-	}
-	
-	return nil
+	return g.updateScenes()
 }
 
 func (g *Game) handleGlobalInput() {
-	// Fullscreen toggle
-	if inpututil.IsKeyJustPressed(ebiten.KeyF11) {
-		g.isFullscreen = !g.isFullscreen
-		ebiten.SetFullscreen(g.isFullscreen)
-	}
-
-	// Escape key handling
-	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
-		switch g.state {
-		case StatePlaying:
-			g.state = StateMenu
-			g.bgPlayer.Pause()
-		case StatePaused:
-			g.state = StateMenu
-		case StateMenu:
-			if g.score > 0 { // Game in progress
-				g.state = StatePlaying
-				g.bgPlayer.Play()
-			} else {
-				g.state = StateTitleScreen
-			}
-		}
+	// A key being captured by the binding editor belongs to the editor
+	if g.capturing {
+		return
+	}
+
+	// While typing, only the real Escape key backs out. The binding editor
+	// is always left with Escape too, as Back itself may be rebound or unbound.
+	back := g.pressed(ActionBack) || g.pointerBack()
+	switch {
+	case g.typing():
+		back = inpututil.IsKeyJustPressed(ebiten.KeyEscape)
+	case g.editingControls:
+		back = inpututil.IsKeyJustPressed(ebiten.KeyEscape) || g.pointerBack()
+	case g.pressed(ActionFullscreen):
+		g.toggleFullscreen()
+	}
+
+	// Back closes the top scene, or opens the menu over a run
+	if back {
+		g.back()
 	}
 }
 
 func (g *Game) updateTitleScreen() error {
-	g.renderer.time += 0.016
-	
-	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+	if g.pressed(ActionConfirm) {
 		g.resetGameplay()
 	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyS) {
-		g.state = StateMenu
-		g.menuOption = 2 // Statistics option
+	if g.pressed(ActionLeaderboards) {
+		g.openMenuAt(tr("menu.leaderboards"))
 	}
+
+	// Profile picker
+	if g.pressed(ActionLeft) {
+		g.switchProfile(-1)
+	}
+	if g.pressed(ActionRight) {
+		g.switchProfile(1)
+	}
+	g.updateUI(g.titleView())
 	return nil
 }
 
-func (g *Game) updateMenu() error {
-	if inpututil.IsKeyJustPressed(ebiten.KeyArrowUp) || inpututil.IsKeyJustPressed(ebiten.KeyW) {
-		g.menuOption = (g.menuOption - 1 + 4) % 4
-	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyArrowDown) || inpututil.IsKeyJustPressed(ebiten.KeyS) {
-		g.menuOption = (g.menuOption + 1) % 4
+func (g *Game) switchProfile(delta int) {
+	if g.profileLocked() {
+		return
 	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeySpace) {
-		switch g.menuOption {
-		case 0: // Resume/New Game
-			if g.state == StateGameOver || g.score == 0 {
-				g.resetGameplay()
-			} else {
-				g.state = StatePlaying
-				g.bgPlayer.Play()
-			}
-		case 1: // New Game
-			g.resetGameplay()
-		case 2: // Reset Stats
-			g.gameData = GameData{}
+	count := len(g.profiles.Profiles)
+	g.selectProfile((g.profiles.Active + delta + count) % count)
+	g.saveGameData()
+}
+
+// menuItems are the menu's entries. Value entries cycle on click and also
+// take Left/Right.
+func (g *Game) menuItems() []*Button {
+	resume := &Button{Label: tr("menu.start"), OnClick: g.resetGameplay}
+	if g.runUnderneath() {
+		resume = &Button{Label: tr("menu.resume"), OnClick: g.popScene}
+	}
+
+	return []*Button{
+		resume,
+		{Label: tr("menu.new_game"), OnClick: g.openModeSelect},
+		{Label: tr("menu.difficulty", difficultyByID(g.profile.Difficulty).Title()), OnAdjust: g.cycleDifficulty},
+		{Label: tr("menu.theme", palette.Name), OnAdjust: g.cycleTheme},
+		{Label: tr("menu.skin", g.currentSkin().Name), OnAdjust: g.cycleSkin},
+		{Label: tr("menu.accessibility"), OnClick: g.openAccessibility},
+		{Label: tr("menu.options"), OnClick: g.openOptions},
+		{Label: tr("menu.challenges"), OnClick: g.openChallenges},
+		{Label: tr("menu.leaderboards"), OnClick: g.openLeaderboards},
+		{Label: tr("menu.profiles"), OnClick: g.openProfiles},
+		{Label: tr("menu.reset_stats"), OnClick: func() {
+			// Active profile only. Challenge results stay: they hold the
+			// streaks and enforce one scored attempt per period.
+			*g.gameData = GameData{}
+			g.profile.Unlocks = nil
 			g.saveGameData()
-		case 3: // Back to Title
-			g.state = StateTitleScreen
-			g.bgPlayer.Pause()
+		}},
+		{Label: tr("menu.back_to_title"), OnClick: func() { g.setScene(&titleScene{}) }},
+	}
+}
+
+// openMenuAt opens the menu with the named entry selected.
+func (g *Game) openMenuAt(label string) {
+	g.pushScene(&menuScene{})
+	g.menuOption = 0
+	for i, item := range g.menuItems() {
+		if item.Label == label {
+			g.menuOption = i
 		}
 	}
+}
+
+// cycleDifficulty steps through the presets. It applies from the next run.
+func (g *Game) cycleDifficulty(delta int) {
+	current := 0
+	for i, d := range difficulties {
+		if d.ID == g.profile.Difficulty {
+			current = i
+		}
+	}
+	g.profile.Difficulty = difficulties[(current+delta+len(difficulties))%len(difficulties)].ID
+	g.saveGameData()
+}
+
+func (g *Game) updateMenu() error {
+	g.updateUI(g.menuView())
 	return nil
 }
 
 func (g *Game) updatePaused() error {
-	if inpututil.IsKeyJustPressed(ebiten.KeyP) {
-		g.state = StatePlaying
-		g.bgPlayer.Play()
+	_, tapped := g.tapped()
+	if g.pressed(ActionPause) || tapped {
+		g.popScene()
 	}
 	return nil
 }
 
 func (g *Game) updateGameOver() error {
-	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeyR) {
-		// Update stats
-		g.gameData.TotalGames++
-		g.gameData.TotalScore += g.score
-		if g.score > g.gameData.HighScore {
-			g.gameData.HighScore = g.score
-		}
-		if g.maxCombo > g.gameData.BestCombo {
-			g.gameData.BestCombo = g.maxCombo
-		}
-		g.gameData.PlayTime += int64(time.Since(g.gameStartTime).Seconds())
-		g.saveGameData()
-		g.resetGameplay()
+	if g.enteringName {
+		g.updateNameEntry()
+		return nil
+	}
+	_, tapped := g.tapped()
+	if g.pressed(ActionConfirm) || g.pressed(ActionRestart) || tapped {
+		g.restartRun()
 	}
 	return nil
 }
 
+// recordRunStats adds the finished run to the profile's statistics. It runs
+// when the game-over screen closes, whichever way the player leaves it.
+func (g *Game) recordRunStats() {
+	g.gameData.TotalGames++
+	g.gameData.TotalScore += g.score
+	if g.score > g.gameData.HighScore {
+		g.gameData.HighScore = g.score
+	}
+	if g.maxCombo > g.gameData.BestCombo {
+		g.gameData.BestCombo = g.maxCombo
+	}
+	g.gameData.PlayTime += int64(time.Since(g.gameStartTime).Seconds())
+	g.saveGameData()
+}
+
 func (g *Game) updateGameplay() error {
 	// Pause toggle
-	if inpututil.IsKeyJustPressed(ebiten.KeyP) {
-		g.state = StatePaused
-		g.bgPlayer.Pause()
+	if g.pressed(ActionPause) {
+		g.pushScene(&pauseScene{})
 		return nil
 	}
 
+	prevDir, prevSpeed := g.nextDir, g.baseSpeed
+
 	// Speed controls
-	if inpututil.IsKeyJustPressed(ebiten.KeyEqual) || inpututil.IsKeyJustPressed(ebiten.KeyNumpadAdd) {
+	if g.pressed(ActionSpeedUp) {
 		if g.baseSpeed > minSpeed {
 			g.baseSpeed--
 		}
 	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyMinus) {
+	if g.pressed(ActionSpeedDown) {
 		if g.baseSpeed < maxSpeed {
 			g.baseSpeed++
 		}
@@ -819,24 +887,49 @@ func (g *Game) updateGameplay() error {
 
 	// Movement input
 	dir := g.dir
-	if inpututil.IsKeyJustPressed(ebiten.KeyArrowUp) || inpututil.IsKeyJustPressed(ebiten.KeyW) {
+	if g.pressed(ActionUp) {
 		if dir.Y != 1 { g.nextDir = Point{0, -1} }
 	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyArrowDown) || inpututil.IsKeyJustPressed(ebiten.KeyS) {
+	if g.pressed(ActionDown) {
 		if dir.Y != -1 { g.nextDir = Point{0, 1} }
 	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) || inpututil.IsKeyJustPressed(ebiten.KeyA) {
+	if g.pressed(ActionLeft) {
 		if dir.X != 1 { g.nextDir = Point{-1, 0} }
 	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) || inpututil.IsKeyJustPressed(ebiten.KeyD) {
+	if g.pressed(ActionRight) {
 		if dir.X != -1 { g.nextDir = Point{1, 0} }
 	}
+	if d, ok := g.pointerDirection(); ok && d.X != -dir.X && d.Y != -dir.Y {
+		g.nextDir = d
+	}
+
+	// Record input for the leaderboard replay
+	if g.nextDir != prevDir {
+		g.replay.record(ReplayEvent{Frame: g.frame, DX: g.nextDir.X, DY: g.nextDir.Y})
+	}
+	if g.baseSpeed != prevSpeed {
+		g.replay.record(ReplayEvent{Frame: g.frame, Speed: g.baseSpeed})
+	}
+
+	g.step()
+	g.updateCamera()
+	if g.over {
+		// Screen effects stay out of step so headless replays never touch them
+		g.shake(15.0)
+		g.flashHit()
+		g.finishRun()
+		g.pushScene(&gameOverScene{})
+	}
+	return nil
+}
 
+// step advances the simulation by one frame. All gameplay randomness comes
+// from g.rng so the same seed and inputs always produce the same run.
+func (g *Game) step() {
 	// Update game state
 	g.frame++
 	g.foodPulse += 0.08
 	g.headPulse += 0.1
-	g.renderer.time += 0.016
 	
 	// Update timers
 	if g.speedBoostTime > 0 {
@@ -853,8 +946,13 @@ func (g *Game) updateGameplay() error {
 		g.invulnerable--
 	}
 	
-	if g.shakeIntensity > 0 {
-		g.shakeIntensity *= 0.9
+	if g.levelUpTimer > 0 {
+		g.levelUpTimer--
+	}
+	
+	// Time limits, hunger and pace for the non-endless modes
+	if g.updateMode() {
+		return
 	}
 
 	// Update power-up
@@ -863,14 +961,7 @@ func (g *Game) updateGameplay() error {
 		g.powerUp.pulse += 0.12
 		
 		// Add sparkle effects to power-ups
-		if g.frame % 10 == 0 {
-			sparkleColor := bonusColor
-			switch g.powerUp.type_ {
-			case 1: sparkleColor = color.RGBA{100, 255, 100, 255}
-			case 2: sparkleColor = color.RGBA{100, 100, 255, 255}
-			}
-			g.addParticles(g.powerUp.pos, 1, sparkleColor)
-		}
+		g.sparkles.update(g, &sparkleParticles, g.powerUp.pos, palette.PowerUps[g.powerUp.type_].rgba())
 		
 		if g.powerUp.timer <= 0 {
 			g.powerUp.active = false
@@ -883,28 +974,38 @@ func (g *Game) updateGameplay() error {
 
 	// Game movement logic
 	if g.frame%g.speed != 0 {
-		return nil
+		return
 	}
 
 	g.dir = g.nextDir
 	head := g.snake[0]
 	newHead := Point{(head.X + g.dir.X + g.gridW) % g.gridW, (head.Y + g.dir.Y + g.gridH) % g.gridH}
 
+	// Solid walls end the run unless the shield lets the snake wrap through
+	if g.rules.Walls && g.invulnerable == 0 {
+		next := Point{head.X + g.dir.X, head.Y + g.dir.Y}
+		if next.X < 0 || next.Y < 0 || next.X >= g.gridW || next.Y >= g.gridH {
+			g.endRun(head, endWall)
+			return
+		}
+	}
+
 	// Check collision with snake body
 	if g.invulnerable == 0 {
 		for _, s := range g.snake {
 			if s == newHead {
-				g.state = StateGameOver
-				g.gameOverPlayer.Rewind()
-				g.gameOverPlayer.Play()
-				g.shakeIntensity = 15.0
-				g.addParticles(newHead, 15, color.RGBA{255, 100, 100, 255})
-				return nil
+				g.endRun(newHead, endCrash)
+				return
 			}
 		}
+		if g.isHazard(newHead) {
+			g.endRun(newHead, endHazard)
+			return
+		}
 	}
 
 	// Move snake
+	g.recordMove()
 	g.snake = append([]Point{newHead}, g.snake...)
 	
 	// Update trail opacity
@@ -916,8 +1017,8 @@ func (g *Game) updateGameplay() error {
 	}
 
 	// Check food collision
-	if newHead == g.food {
-		g.grow += 2
+	if g.foodActive && newHead == g.food {
+		g.grow += g.rules.GrowthPerFood
 		g.combo++
 		if g.combo > g.maxCombo {
 			g.maxCombo = g.combo
@@ -929,18 +1030,18 @@ func (g *Game) updateGameplay() error {
 		
 		// Play appropriate sound
 		if g.combo > 3 {
-			g.comboPlayer.Rewind()
-			g.comboPlayer.Play()
+			g.playSound(g.comboPlayer)
 		} else {
-			g.eatPlayer.Rewind()
-			g.eatPlayer.Play()
+			g.playSound(g.eatPlayer)
 		}
 		
-		// Add particles - red for food
-		particleCount := 8 + g.combo/2
-		g.addParticles(g.food, particleCount, foodColor)
+		// Add particles - red for food, with sparks flying up during a combo
+		g.emit(&eatParticles, g.food, palette.Food.rgba())
+		if g.combo > 1 {
+			g.emitN(&comboParticles, g.food, int(math.Min(float64(g.combo*2), 30)), palette.FoodCombo.rgba())
+		}
 		
-		g.placeFood()
+		g.onFoodEaten()
 	} else {
 		g.comboTimer--
 		if g.comboTimer <= 0 {
@@ -950,9 +1051,8 @@ func (g *Game) updateGameplay() error {
 
 	// Check power-up collision
 	if g.powerUp.active && newHead == g.powerUp.pos {
-		g.powerUpPlayer.Rewind()
-		g.powerUpPlayer.Play()
-		g.addParticles(g.powerUp.pos, 12, bonusColor)
+		g.playSound(g.powerUpPlayer)
+		g.emit(&powerUpParticles, g.powerUp.pos, palette.PowerUps[g.powerUp.type_].rgba())
 		
 		switch g.powerUp.type_ {
 		case 0: // Bonus points
@@ -971,42 +1071,50 @@ func (g *Game) updateGameplay() error {
 	} else if len(g.snake) > 1 {
 		g.snake = g.snake[:len(g.snake)-1]
 	}
+	if len(g.snake) > g.peakLength {
+		g.peakLength = len(g.snake)
+	}
 
-	return nil
+	g.updateLevel()
+}
+
+func (g *Game) endRun(at Point, reason string) {
+	g.over = true
+	g.endReason = reason
+	g.playSound(g.gameOverPlayer)
+	g.emit(&deathParticles, at, palette.Crash.rgba())
+	g.replay.Frames = g.frame
 }
 
 // ==================== RENDERING SYSTEM ====================
 
+// drawEnhancedCell adds an item cell, its shadow and highlight to the
+// renderer's batch. The caller flushes it.
 func (g *Game) drawEnhancedCell(screen *ebiten.Image, x, y int, c color.RGBA, scale float64, opacity float64) {
 	if opacity <= 0 {
 		return
 	}
 	
+	batch := &g.renderer.batch
+	
 	// Calculate screen position with centering offset
-	offsetX := (g.screenWidth - g.gridW*g.cellSize) / 2
-	offsetY := (g.screenHeight - g.gridH*g.cellSize) / 2
+	offsetX, offsetY := g.arenaOrigin()
 	
 	size := float64(g.cellSize) * scale
 	cellOffset := float64(g.cellSize) * (1-scale) / 2
-	posX := float64(offsetX + x*g.cellSize) + cellOffset
-	posY := float64(offsetY + y*g.cellSize) + cellOffset
-	
-	// Apply screen shake
-	if g.shakeIntensity > 0 {
-		posX += (g.rng.Float64() - 0.5) * g.shakeIntensity
-		posY += (g.rng.Float64() - 0.5) * g.shakeIntensity
-	}
+	posX := offsetX + float64(x*g.cellSize) + cellOffset
+	posY := offsetY + float64(y*g.cellSize) + cellOffset
 	
 	// Draw shadow first
 	shadowOffset := 2.0 * g.scaleFactor
-	shadowColor := color.RGBA{0, 0, 0, uint8(float64(shadowColor.A) * opacity * 0.3)}
-	ebitenutil.DrawRect(screen, posX+shadowOffset, posY+shadowOffset, size, size, shadowColor)
+	shadowColor := color.RGBA{palette.Shadow.R, palette.Shadow.G, palette.Shadow.B, uint8(float64(palette.Shadow.A) * opacity * 0.3)}
+	batch.rect(screen, posX+shadowOffset, posY+shadowOffset, size, size, shadowColor)
 	
 	// Apply opacity to main color
 	finalColor := color.RGBA{c.R, c.G, c.B, uint8(float64(c.A) * opacity)}
 	
 	// Draw main cell
-	ebitenutil.DrawRect(screen, posX, posY, size, size, finalColor)
+	batch.rect(screen, posX, posY, size, size, finalColor)
 	
 	// Highlight effect for certain cells
 	if scale > 0.95 {
@@ -1018,435 +1126,352 @@ func (g *Game) drawEnhancedCell(screen *ebiten.Image, x, y int, c color.RGBA, sc
 		}
 		highlightSize := size * 0.4
 		highlightOffset := size * 0.1
-		ebitenutil.DrawRect(screen, posX+highlightOffset, posY+highlightOffset, highlightSize, highlightSize, highlightColor)
-	}
-}
-
-func (g *Game) drawParticles(screen *ebiten.Image) {
-	for _, p := range g.particles {
-		if p.life > 0 {
-			alpha := float32(p.color.A) * float32(p.life)
-			particleColor := color.RGBA{p.color.R, p.color.G, p.color.B, uint8(alpha)}
-
-			x := p.pos.X
-			y := p.pos.Y
-			size := p.size
-
-			// Apply screen shake to particles too
-			if g.shakeIntensity > 0 {
-				x += (g.rng.Float64() - 0.5) * g.shakeIntensity * 0.5
-				y += (g.rng.Float64() - 0.5) * g.shakeIntensity * 0.5
-			}
-
-			// Simple rectangular particle for now
-			ebitenutil.DrawRect(screen,
-				x - size/2,
-				y - size/2,
-				size, size, particleColor)
-		}
+		batch.rect(screen, posX+highlightOffset, posY+highlightOffset, highlightSize, highlightSize, highlightColor)
 	}
 }
 
 func (g *Game) Draw(screen *ebiten.Image) {
+	// Post-processing draws the frame offscreen first, see postfx.go
+	frame := g.beginPost(screen)
+	
 	// Always draw the space background
-	g.renderer.drawSpaceBackground(screen)
+	g.renderer.drawSpaceBackground(frame)
 	
-	switch g.state {
-	case StateTitleScreen:
-		g.drawTitleScreen(screen)
-	case StateMenu:
-		g.drawMenuScreen(screen)
-	case StatePlaying, StatePaused, StateGameOver:
-		g.drawGameplay(screen)
-		if g.state == StatePaused {
-			g.drawPauseOverlay(screen)
-		} else if g.state == StateGameOver {
-			g.drawGameOverOverlay(screen)
-		}
+	// Scenes draw their menus and overlays at the accessibility UI scale
+	g.drawScenes(frame)
+	if g.showBackButton() {
+		g.drawBackButton(frame)
+	}
+	if g.padToastTimer > 0 {
+		g.drawPadToast(frame)
 	}
+	
+	g.endPost(screen, frame)
 }
 
 func (g *Game) drawGameplay(screen *ebiten.Image) {
+	skin := g.currentSkin()
+
 	// Draw power-up
 	if g.powerUp.active {
 		pulse := 0.8 + 0.2*math.Sin(g.powerUp.pulse)
-		var powerColor color.RGBA
-		switch g.powerUp.type_ {
-		case 0: powerColor = bonusColor
-		case 1: powerColor = color.RGBA{120, 255, 120, 255}
-		case 2: powerColor = color.RGBA{120, 120, 255, 255}
+		powerColor := palette.PowerUps[g.powerUp.type_].rgba()
+		if skin.sprite() {
+			g.drawSpriteItem(screen, skin, g.powerUp.pos, tilePowerUp+g.powerUp.type_, pulse)
+		} else {
+			g.drawEnhancedCell(screen, g.powerUp.pos.X, g.powerUp.pos.Y, powerColor, pulse, 1.0)
 		}
-		g.drawEnhancedCell(screen, g.powerUp.pos.X, g.powerUp.pos.Y, powerColor, pulse, 1.0)
 	}
 
-	// Draw food with enhanced visibility - bright red with white border
-	pulse := 1.0 + 0.2*math.Sin(g.foodPulse*2) // Stronger pulse for visibility
-	
-	// Draw white border for maximum visibility
-	borderColor := color.RGBA{255, 255, 255, 200}
-	g.drawEnhancedCell(screen, g.food.X, g.food.Y, borderColor, pulse*1.2, 1.0)
-	
-	// Draw bright red core
-	currentFoodColor := foodColor
-	if g.combo > 0 {
-		// Alternate between bright red and bright yellow for combo
-		if int(g.foodPulse*4)%2 == 0 {
-			currentFoodColor = color.RGBA{255, 255, 50, 255} // Bright yellow
-		} else {
-			currentFoodColor = color.RGBA{255, 50, 50, 255}  // Bright red
+	// Draw asteroid hazards
+	for _, h := range g.hazards {
+		if skin.sprite() {
+			g.drawSpriteItem(screen, skin, h, tileHazard, 1.0)
+			continue
 		}
+		g.drawEnhancedCell(screen, h.X, h.Y, palette.Hazard.rgba(), 0.95, 1.0)
 	}
-	g.drawEnhancedCell(screen, g.food.X, g.food.Y, currentFoodColor, pulse, 1.0)
 
-	// Draw snake with green theme
-	for i, s := range g.snake {
-		opacity := 1.0
-		if i < len(g.trailOpacity) {
-			opacity = g.trailOpacity[i]
-		}
+	// Draw food with enhanced visibility - bright red with white border
+	if g.foodActive && skin.sprite() {
+		g.drawSpriteItem(screen, skin, g.food, tileFood, 1.0+0.15*math.Sin(g.foodPulse*2))
+	} else if g.foodActive {
+		pulse := 1.0 + 0.2*math.Sin(g.foodPulse*2) // Stronger pulse for visibility
 		
-		if i == 0 {
-			// Enhanced head with pulsing effect
-			headScale := 1.0 + 0.1*math.Sin(g.headPulse)
-			currentHeadColor := headColor
-			
-			// Special effects based on power-ups
-			if g.invulnerable > 0 {
-				// Flashing invulnerability - green/white
-				if (g.frame/5)%2 == 0 {
-					currentHeadColor = color.RGBA{200, 255, 200, 255}
-				}
-			} else if g.speedBoostTime > 0 {
-				currentHeadColor = color.RGBA{150, 255, 100, 255} // Brighter green
-			} else if g.slowMotionTime > 0 {
-				currentHeadColor = color.RGBA{100, 150, 100, 255} // Darker green
-			}
-			
-			g.drawEnhancedCell(screen, s.X, s.Y, currentHeadColor, headScale, opacity)
-		} else {
-			// Body with gradient effect
-			bodyScale := 0.9 - float64(i)*0.01
-			if bodyScale < 0.5 { bodyScale = 0.5 }
-			
-			// Gradient body color - green theme
-			factor := float64(i) / float64(len(g.snake))
-			currentBodyColor := color.RGBA{
-				uint8(float64(bodyColor.R) * (1 - factor*0.4)),
-				uint8(float64(bodyColor.G) * (1 - factor*0.3)),
-				uint8(float64(bodyColor.B) * (1 - factor*0.4)),
-				bodyColor.A,
+		// Draw white border for maximum visibility
+		borderColor := color.RGBA{255, 255, 255, 200}
+		g.drawEnhancedCell(screen, g.food.X, g.food.Y, borderColor, pulse*1.2, 1.0)
+		
+		// Draw bright red core
+		currentFoodColor := palette.Food.rgba()
+		if g.combo > 0 {
+			// Alternate between bright red and bright yellow for combo
+			if g.flashOn(int(g.foodPulse * 4)) {
+				currentFoodColor = palette.FoodCombo.rgba()
+			} else {
+				currentFoodColor = palette.Food.rgba()
 			}
-			
-			g.drawEnhancedCell(screen, s.X, s.Y, currentBodyColor, bodyScale, opacity)
+		}
+		g.drawEnhancedCell(screen, g.food.X, g.food.Y, currentFoodColor, pulse, 1.0)
+	}
+
+	// All the item cells go in one draw call, with their glyphs on top
+	g.renderer.batch.flush(screen)
+	if !skin.sprite() {
+		if g.powerUp.active {
+			g.drawItemGlyph(screen, g.powerUp.pos, g.powerUp.type_)
+		}
+		for _, h := range g.hazards {
+			g.drawItemGlyph(screen, h, glyphHazard)
+		}
+		if g.foodActive {
+			g.drawItemGlyph(screen, g.food, glyphFood)
 		}
 	}
 
+	// Draw snake with green theme, sliding smoothly between cells
+	if skin.sprite() {
+		g.drawSpriteSnake(screen, skin)
+	} else {
+		g.drawSmoothSnake(screen)
+	}
+
+	// Bloom on the food and the head, added by the post-processing pipeline
+	cell := float64(g.cellSize)
+	if g.foodActive {
+		x, y := g.cellCenter(g.food)
+		g.glow(x, y, cell*0.8, palette.Food.rgba())
+	}
+	if len(g.snake) > 0 {
+		ox, oy := g.arenaOrigin()
+		head := g.segmentPosition(0, g.moveProgress())
+		g.glow(ox+(head.X+0.5)*cell, oy+(head.Y+0.5)*cell, cell*0.7, g.headDrawColor())
+	}
+	
 	// Draw particles
 	g.drawParticles(screen)
 
-	// Draw HUD
+	// Draw HUD, with a minimap when the arena scrolls
+	g.drawMinimap(screen)
 	g.drawHUD(screen)
 }
 
+// titleView lays out the title screen. The launch, leaderboards and profile
+// lines can also be tapped.
+func (g *Game) titleView() Widget {
+	blank := Gap(13)
+	lines := []Widget{
+		label(tr("title.name"), palette.UI.Selected),
+		blank,
+		label(tr("title.edition"), palette.UI.Danger),
+	}
+	for _, feature := range []string{"arena", "meteors", "theme", "food", "combos", "effects"} {
+		lines = append(lines, label("• "+tr("title.feature."+feature), palette.UI.Item))
+	}
+
+	lines = append(lines,
+		blank,
+		label(tr("title.controls"), palette.UI.Accent),
+		label(tr("title.move", g.keyHint(ActionUp), g.keyHint(ActionLeft), g.keyHint(ActionDown), g.keyHint(ActionRight)), palette.UI.Info),
+		label(tr("title.keys", g.keyHint(ActionPause), g.keyHint(ActionFullscreen), g.keyHint(ActionBack)), palette.UI.Info),
+		label(tr("title.speed", g.keyHint(ActionSpeedUp), g.keyHint(ActionSpeedDown)), palette.UI.Info),
+		blank,
+		label(tr("title.statistics"), palette.UI.Heading),
+		label(tr("title.high_score", g.gameData.HighScore)+" | "+trn("title.games", g.gameData.TotalGames), palette.UI.Soft),
+		label(tr("title.best_combo", g.gameData.BestCombo), palette.UI.Soft),
+		blank,
+		&Label{Text: tr("title.launch", g.keyHint(ActionConfirm)), Color: palette.UI.Title,
+			OnTap: func(int) { g.resetGameplay() }},
+		&Label{Text: tr("title.leaderboards", g.keyHint(ActionLeaderboards)), Color: palette.UI.Title,
+			OnTap: func(int) { g.openMenuAt(tr("menu.leaderboards")) }},
+		blank,
+		&Label{Text: tr("title.profile", g.profile.Name, g.profiles.Active+1, len(g.profiles.Profiles)),
+			Color: palette.UI.Highlight, OnTap: g.switchProfile},
+		label(tr("title.profile_hint"), palette.UI.Text),
+		label(tr("title.mode", modeName(g.selectedMode)), palette.UI.Text),
+	)
+	return &Screen{Children: []Widget{&Panel{Anchor: AnchorCenter, Spacing: 9, Children: lines}}}
+}
+
 func (g *Game) drawTitleScreen(screen *ebiten.Image) {
-	centerX := float64(g.screenWidth) / 2
-	centerY := float64(g.screenHeight) / 2
+	g.renderUI(screen, g.titleView())
+}
 
-	lines := []string{
-		"🌌 COSMIC SNAKE 🐍",
-		"",
-		"🔥 Meteor Storm Edition",
-		"• Dynamic Fullscreen Arena",
-		"• Falling Meteor Background",
-		"• Green/Black/Red Theme",
-		"• Enhanced Food Visibility",
-		"• Combo System & Power-ups",
-		"• Spectacular Visual Effects",
-		"",
-		"🎯 Controls:",
-		"Arrow Keys/WASD: Move",
-		"P: Pause | F11: Fullscreen | Esc: Menu",
-		"+/-: Speed Control",
-		"",
-		"🏆 Statistics:",
-		fmt.Sprintf("High Score: %d | Games: %d", g.gameData.HighScore, g.gameData.TotalGames),
-		fmt.Sprintf("Best Combo: %d", g.gameData.BestCombo),
-		"",
-		"🚀 Press ENTER/SPACE to Launch!",
-		"Press S for Statistics",
-	}
-
-	lineHeight := 22.0
-	totalHeight := float64(len(lines)) * lineHeight
-	startY := centerY - totalHeight/2
-
-	face := basicfont.Face7x13
-
-	for i, line := range lines {
-		if line == "" {
-			continue
-		}
+func (g *Game) menuView() Widget {
+	items := g.menuItems()
+	buttons := make([]Widget, len(items))
+	for i, b := range items {
+		buttons[i] = b
+	}
 
-		approxWidth := float64(len(line)) * 8
-		x := centerX - approxWidth/2
-		y := startY + float64(i)*lineHeight
-
-		// Decide line color based on green/black/red theme
-		var lineColor color.Color = color.RGBA{200, 255, 200, 255} // Light green default
-		switch {
-		case i == 0: // Title
-			lineColor = color.RGBA{0, 255, 100, 255} // Bright green title
-		case i == 2: // "Meteor Storm Edition"
-			lineColor = color.RGBA{255, 100, 100, 255} // Red accent
-		case i >= 3 && i <= 8: // feature list
-			lineColor = color.RGBA{150, 255, 150, 255} // Light green
-		case i == 10: // "Controls"
-			lineColor = color.RGBA{255, 200, 100, 255} // Yellow-orange
-		case i >= 11 && i <= 13: // control list
-			lineColor = color.RGBA{200, 200, 255, 255} // Light blue
-		case i == 15: // "Statistics"
-			lineColor = color.RGBA{255, 150, 150, 255} // Light red
-		case i >= 16 && i <= 17: // stats values
-			lineColor = color.RGBA{255, 255, 200, 255} // Light yellow
-		case i >= 19 && i <= 20: // Launch instructions
-			lineColor = color.RGBA{100, 255, 100, 255} // Bright green
-		}
+	title := tr("menu.title")
+	if _, ok := g.below().(*gameOverScene); ok {
+		title = tr("menu.title_game_over")
+	}
 
-		// Draw line with chosen color
-		text.Draw(screen, line, face, int(x), int(y), lineColor)
+	// Shrink the spacing on short screens so every item fits
+	rowHeight := math.Min(40, float64(g.screenHeight)/g.uiScale()*0.5/float64(len(items)))
+	children := []Widget{
+		label(title, palette.UI.Title),
+		Gap(50),
+		&List{Items: buttons, Cursor: &g.menuOption, RowHeight: rowHeight},
+	}
+
+	// Show current game stats if in game
+	if g.runUnderneath() {
+		children = append(children, Gap(40), &Panel{Spacing: 12, Children: []Widget{
+			label(tr("menu.score", g.score), palette.UI.Text),
+			label(tr("menu.combo", g.combo, g.maxCombo), palette.UI.Text),
+			label(tr("menu.length", len(g.snake)), palette.UI.Text),
+			label(tr("menu.playfield", g.gridW, g.gridH), palette.UI.Text),
+		}})
+	}
+	return &Screen{
+		Backdrop: palette.UI.Overlay,
+		Children: []Widget{&Panel{Anchor: AnchorCenter, Children: children}},
 	}
 }
 
 func (g *Game) drawMenuScreen(screen *ebiten.Image) {
-	// Semi-transparent overlay
-	overlay := ebiten.NewImage(g.screenWidth, g.screenHeight)
-	overlay.Fill(color.RGBA{0, 0, 0, 180})
-	screen.DrawImage(overlay, nil)
+	g.renderUI(screen, g.menuView())
+}
 
-	centerX := float64(g.screenWidth) / 2
-	centerY := float64(g.screenHeight) / 2
+func (g *Game) drawPauseOverlay(screen *ebiten.Image) {
+	instruction := tr("pause.hint", g.keyHint(ActionPause), g.keyHint(ActionBack))
+	g.renderUI(screen, &Screen{
+		Backdrop: color.RGBA{0, 0, 0, 120},
+		Children: []Widget{&Panel{Anchor: AnchorCenter, Spacing: 27, Children: []Widget{
+			label(tr("pause.title"), palette.UI.Title),
+			label(instruction, palette.UI.Text),
+		}}},
+	})
+}
 
-	menuItems := []string{
-		"Resume Game",
-		"New Game",
-		"Reset Statistics",
-		"Back to Title",
+func (g *Game) gameOverView() Widget {
+	gameOverText, summary := g.runSummary()
+	children := []Widget{
+		label(gameOverText, palette.UI.Danger),
+		label(tr("game_over.mode", modeName(g.mode)), palette.UI.Accent),
 	}
-
-	if g.state == StateGameOver || g.score == 0 {
-		menuItems[0] = "Start New Game"
+	for _, line := range summary {
+		children = append(children, label(line, palette.UI.Text))
 	}
 
-	lineHeight := 40.0
-	totalHeight := float64(len(menuItems)) * lineHeight
-	startY := centerY - totalHeight/2
-
-	// Menu title
-	title := "=== COSMIC MENU ==="
-	if g.state == StateGameOver {
-		title = "=== MISSION COMPLETE ==="
+	children = append(children, Gap(12), label(tr("game_over.score", g.score), color.White))
+	if g.score > g.gameData.HighScore {
+		children = append(children, label(tr("game_over.high_score"), palette.UI.Highlight))
 	}
-	titleWidth := float64(len(title)) * 10
-	titleX := centerX - titleWidth/2
-	titleY := startY - 80
 
-	face := basicfont.Face7x13
-
-	// Title in green
-	text.Draw(screen, title, face, int(titleX), int(titleY), color.RGBA{100, 255, 100, 255})
-
-	// Menu items
-	for i, item := range menuItems {
-		approxWidth := float64(len(item)) * 9
-		x := centerX - approxWidth/2
-		y := startY + float64(i)*lineHeight
-
-		if i == g.menuOption {
-			// Selected option in bright green
-			prefix := "► "
-			suffix := " ◄"
-			fullText := prefix + item + suffix
-			fullWidth := float64(len(fullText)) * 9
-			fullX := centerX - fullWidth/2
-
-			text.Draw(screen, fullText, face, int(fullX), int(y), color.RGBA{0, 255, 100, 255})
-		} else {
-			text.Draw(screen, item, face, int(x), int(y), color.RGBA{150, 255, 150, 255})
+	// Leaderboard name prompt, or the rank and instructions
+	children = append(children, Gap(12))
+	if g.enteringName {
+		children = append(children, g.nameEntryView()...)
+	} else {
+		if g.lastRank > 0 {
+			children = append(children, label(tr("game_over.rank", g.lastRank, g.currentBoardKey().Title()), palette.UI.Title))
 		}
+		instruction := tr("game_over.hint", g.keyHint(ActionConfirm), g.keyHint(ActionRestart), g.keyHint(ActionBack))
+		children = append(children, label(instruction, palette.UI.Text))
 	}
 
-	// Show current game stats if in game
-	if g.state != StateGameOver && g.score > 0 {
-		statsY := startY + float64(len(menuItems))*lineHeight + 60
-		stats := []string{
-			fmt.Sprintf("Current Score: %d", g.score),
-			fmt.Sprintf("Current Combo: %d (Max: %d)", g.combo, g.maxCombo),
-			fmt.Sprintf("Snake Length: %d", len(g.snake)),
-			fmt.Sprintf("Playfield: %dx%d", g.gridW, g.gridH),
-		}
-
-		for i, stat := range stats {
-			statWidth := float64(len(stat)) * 8
-			statX := centerX - statWidth/2
-			statY := statsY + float64(i)*25
-			text.Draw(screen, stat, face, int(statX), int(statY), color.RGBA{200, 255, 200, 255})
-		}
+	// Newly earned profile unlocks
+	if len(g.newUnlocks) > 0 {
+		children = append(children, Gap(12))
+	}
+	for _, name := range g.newUnlocks {
+		children = append(children, label(tr("game_over.unlocked", name), palette.UI.Accent))
 	}
-}
-
-func (g *Game) drawPauseOverlay(screen *ebiten.Image) {
-	// Semi-transparent overlay
-	overlay := ebiten.NewImage(g.screenWidth, g.screenHeight)
-	overlay.Fill(color.RGBA{0, 0, 0, 120})
-	screen.DrawImage(overlay, nil)
-
-	centerX := float64(g.screenWidth) / 2
-	centerY := float64(g.screenHeight) / 2
-
-	pauseText := "⏸️ PAUSED"
-	textWidth := float64(len(pauseText)) * 15
-
-	face := basicfont.Face7x13
-
-	// Main pause text in green
-	text.Draw(screen, pauseText, face, int(centerX-textWidth/2), int(centerY), color.RGBA{100, 255, 100, 255})
 
-	// Instructions
-	instruction := "Press P to Resume or ESC for Menu"
-	instrWidth := float64(len(instruction)) * 8
-	text.Draw(screen, instruction, face, int(centerX-instrWidth/2), int(centerY+40), color.RGBA{200, 255, 200, 255})
+	return &Screen{
+		Backdrop: palette.UI.GameOverOverlay,
+		Children: []Widget{&Panel{Anchor: AnchorCenter, Spacing: 6, Children: children}},
+	}
 }
 
 func (g *Game) drawGameOverOverlay(screen *ebiten.Image) {
-	// Semi-transparent red overlay
-	overlay := ebiten.NewImage(g.screenWidth, g.screenHeight)
-	overlay.Fill(color.RGBA{50, 0, 0, 150})
-	screen.DrawImage(overlay, nil)
-
-	centerX := float64(g.screenWidth) / 2
-	centerY := float64(g.screenHeight) / 2
-
-	face := basicfont.Face7x13
-
-	// Game Over text in red
-	gameOverText := "💀 MISSION FAILED 💀"
-	textWidth := float64(len(gameOverText)) * 12
-	text.Draw(screen, gameOverText, face, int(centerX-textWidth/2), int(centerY-50), color.RGBA{255, 100, 100, 255})
-
-	// Final score in white
-	finalScore := fmt.Sprintf("Final Score: %d", g.score)
-	scoreWidth := float64(len(finalScore)) * 10
-	text.Draw(screen, finalScore, face, int(centerX-scoreWidth/2), int(centerY), color.White)
-
-	// High score notification
-	if g.score > g.gameData.HighScore {
-		newRecord := "🏆 NEW HIGH SCORE! 🏆"
-		recordWidth := float64(len(newRecord)) * 10
-		text.Draw(screen, newRecord, face, int(centerX-recordWidth/2), int(centerY+30), color.RGBA{255, 255, 100, 255})
-	}
-
-	// Instructions
-	instruction := "Press ENTER/R to Restart or ESC for Menu"
-	instrWidth := float64(len(instruction)) * 8
-	text.Draw(screen, instruction, face, int(centerX-instrWidth/2), int(centerY+80), color.RGBA{200, 255, 200, 255})
+	g.renderUI(screen, g.gameOverView())
 }
 
-func (g *Game) drawHUD(screen *ebiten.Image) {
-	padding := 15.0
-	lineHeight := 18.0
-	
-	// Main HUD with green theme
+// hudView sizes the HUD box to its contents, so long lines never spill out.
+func (g *Game) hudView() Widget {
 	lines := []string{
-		fmt.Sprintf("Score: %d | High: %d | Speed: %d", g.score, g.gameData.HighScore, maxSpeed-g.baseSpeed+minSpeed),
-		fmt.Sprintf("Length: %d | Combo: %dx (Best: %dx)", len(g.snake), g.combo, g.maxCombo),
-		fmt.Sprintf("Arena: %dx%d", g.gridW, g.gridH),
+		tr("hud.score", g.score, g.gameData.HighScore, maxSpeed-g.baseSpeed+minSpeed),
+		tr("hud.length", len(g.snake), g.combo, g.maxCombo),
+		tr("hud.arena", g.gridW, g.gridH),
+	}
+	if level := g.levelHUDLine(); level != "" {
+		lines = append(lines, level)
+	}
+	lines = append(lines, g.modeHUDLines()...)
+	if g.challenge != nil {
+		label := g.challenge.Title()
+		if g.practice {
+			label += " " + tr("hud.practice")
+		}
+		lines = append(lines, label)
 	}
-	
+
 	// Status effects with icons
-	var effects []string
 	if g.speedBoostTime > 0 {
-		effects = append(effects, fmt.Sprintf("🚀 BOOST: %ds", g.speedBoostTime/60+1))
+		lines = append(lines, tr("hud.boost", g.speedBoostTime/60+1))
 	}
 	if g.slowMotionTime > 0 {
-		effects = append(effects, fmt.Sprintf("🐌 SLOW: %ds", g.slowMotionTime/60+1))
+		lines = append(lines, tr("hud.slow", g.slowMotionTime/60+1))
 	}
 	if g.invulnerable > 0 {
-		effects = append(effects, fmt.Sprintf("🛡️ SHIELD: %ds", g.invulnerable/60+1))
+		lines = append(lines, tr("hud.shield", g.invulnerable/60+1))
 	}
-	
+
 	// Power-up indicator
 	if g.powerUp.active {
-		powerUpNames := []string{"💰 BONUS", "🚀 SPEED", "🛡️ SHIELD"}
-		effects = append(effects, fmt.Sprintf("%s: %ds", powerUpNames[g.powerUp.type_], g.powerUp.timer/60+1))
+		powerUpIDs := []string{"hud.powerup.bonus", "hud.powerup.speed", "hud.powerup.shield"}
+		lines = append(lines, tr(powerUpIDs[g.powerUp.type_], g.powerUp.timer/60+1))
 	}
-	
-	lines = append(lines, effects...)
-	
+
 	// Controls hint for new players
 	if g.frame < 360 { // Show for first 6 seconds
-		lines = append(lines, "F11: Fullscreen | ESC: Menu | P: Pause | +/-: Speed")
+		lines = append(lines, tr("hud.controls",
+			g.keyHint(ActionFullscreen), g.keyHint(ActionBack), g.keyHint(ActionPause), g.keyHint(ActionSpeedUp), g.keyHint(ActionSpeedDown)))
 	}
-	
-	// Draw HUD with dark background
-	hudHeight := float64(len(lines)) * lineHeight + padding*2
-	hudBg := color.RGBA{0, 20, 0, 150} // Dark green background
-	ebitenutil.DrawRect(screen, 0, 0, 400, hudHeight, hudBg)
-	
-	for i, line := range lines {
-		y := padding + float64(i)*lineHeight
-		// Draw text in bright green
-		face := basicfont.Face7x13
-		text.Draw(screen, line, face, int(padding), int(y), color.RGBA{100, 255, 100, 255})
+
+	children := make([]Widget, 0, len(lines)+2)
+	for _, line := range lines {
+		children = append(children, &Label{Text: line, Color: palette.UI.HUDText, Align: AnchorLeft})
 	}
-	
+
 	// Progress bars for effects
-	barY := padding + float64(len(lines))*lineHeight + 10
-	barWidth := 250.0
-	barHeight := 6.0
-	
 	if g.speedBoostTime > 0 {
-		progress := float64(g.speedBoostTime) / 300.0
-		// Background
-		ebitenutil.DrawRect(screen, padding, barY, barWidth, barHeight, color.RGBA{20, 20, 20, 180})
-		// Progress in bright green
-		ebitenutil.DrawRect(screen, padding, barY, barWidth*progress, barHeight, color.RGBA{100, 255, 100, 255})
-		barY += barHeight + 8
+		children = append(children, &Bar{Value: float64(g.speedBoostTime) / 300.0, Width: 250, Color: palette.UI.BarSpeed})
 	}
-	
 	if g.invulnerable > 0 {
-		progress := float64(g.invulnerable) / 180.0
-		// Background
-		ebitenutil.DrawRect(screen, padding, barY, barWidth, barHeight, color.RGBA{20, 20, 20, 180})
-		// Progress in blue
-		ebitenutil.DrawRect(screen, padding, barY, barWidth*progress, barHeight, color.RGBA{100, 150, 255, 255})
+		children = append(children, &Bar{Value: float64(g.invulnerable) / 180.0, Width: 250, Color: palette.UI.BarShield})
 	}
+
+	return &Panel{Anchor: AnchorTopLeft, Padding: 15, Spacing: 5, Background: palette.UI.HUDBackground, Children: children}
+}
+
+func (g *Game) drawHUD(screen *ebiten.Image) {
+	g.renderUIAt(screen, g.hudView(), g.viewScale()*scalePercent(g.access().HUDScale))
 }
 
 func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
 	g.screenWidth = outsideWidth
 	g.screenHeight = outsideHeight
 	
-	// Recalculate playfield dimensions when window size changes
-	if g.state == StatePlaying || g.state == StatePaused {
-		g.calculatePlayfieldDimensions()
+	// A run sized before the window was known is sized again now, as long
+	// as the snake has not moved yet
+	if g.awaitingWindow && outsideWidth > 0 && outsideHeight > 0 {
+		g.awaitingWindow = false
+		if g.frame == 0 {
+			g.chooseArena()
+			g.startRun(g.seed)
+		}
 	}
 	
+	// The arena keeps its size for the whole run; only the view rescales
+	g.fitArena()
+	
 	return outsideWidth, outsideHeight
 }
 
 // ==================== MAIN FUNCTION ====================
 
 func main() {
+	// Headless subcommands
+	if len(os.Args) > 1 && os.Args[1] == "verify" {
+		os.Exit(runVerifyCommand(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "bench" {
+		os.Exit(runBenchCommand(os.Args[2:]))
+	}
+
 	ebiten.SetWindowSize(1280, 720)
-	ebiten.SetWindowTitle("Cosmic Snake - Meteor Storm Edition")
 	ebiten.SetWindowResizable(true)
 	ebiten.SetWindowSizeLimits(800, 600, -1, -1)
 	
-	// Start in fullscreen for the best experience
-	ebiten.SetFullscreen(true)
-	
+	// Window mode, size, vsync and language come from the saved settings
 	game := NewGame()
-	game.isFullscreen = true
+	game.applyDisplaySettings()
 	
 	if err := ebiten.RunGame(game); err != nil {
 		log.Fatal(err)
@@ -0,0 +1,310 @@
+package main
+
+// ==================== ENGLISH MESSAGES ====================
+
+// englishMessages is the built-in catalogue and the fallback for anything a
+// locale file leaves out. assets/locales/ru.json translates every ID and is
+// the template for new languages.
+var englishMessages = map[string]string{
+	"window.title": "Cosmic Snake - Meteor Storm Edition",
+
+	"common.on":     "On",
+	"common.off":    "Off",
+	"common.back":   "Back",
+	"common.cancel": "Cancel",
+
+	// Title screen
+	"title.name":            "🌌 COSMIC SNAKE 🐍",
+	"title.edition":         "🔥 Meteor Storm Edition",
+	"title.feature.arena":   "Dynamic Fullscreen Arena",
+	"title.feature.meteors": "Falling Meteor Background",
+	"title.feature.theme":   "Green/Black/Red Theme",
+	"title.feature.food":    "Enhanced Food Visibility",
+	"title.feature.combos":  "Combo System & Power-ups",
+	"title.feature.effects": "Spectacular Visual Effects",
+	"title.controls":        "🎯 Controls:",
+	"title.move":            "Move: %s %s %s %s",
+	"title.keys":            "%s: Pause | %s: Fullscreen | %s: Menu",
+	"title.speed":           "%s / %s: Speed Control",
+	"title.statistics":      "🏆 Statistics:",
+	"title.high_score":      "High Score: %d",
+	"title.games.one":       "%d game",
+	"title.games.other":     "%d games",
+	"title.best_combo":      "Best Combo: %d",
+	"title.launch":          "🚀 Press %s to Launch!",
+	"title.leaderboards":    "Press %s for Leaderboards",
+	"title.profile":         "👤 Profile: < %s >  (%d/%d)",
+	"title.profile_hint":    "LEFT/RIGHT or tap the arrows: Switch Profile",
+	"title.mode":            "Mode: %s (Menu > New Game to change)",
+
+	// Menu
+	"menu.title":           "=== COSMIC MENU ===",
+	"menu.title_game_over": "=== MISSION COMPLETE ===",
+	"menu.start":           "Start New Game",
+	"menu.resume":          "Resume Game",
+	"menu.new_game":        "New Game",
+	"menu.difficulty":      "Difficulty: %s",
+	"menu.theme":           "Theme: %s",
+	"menu.skin":            "Skin: %s",
+	"menu.accessibility":   "Accessibility",
+	"menu.options":         "Options",
+	"menu.challenges":      "Challenges",
+	"menu.leaderboards":    "Leaderboards",
+	"menu.profiles":        "Profiles",
+	"menu.reset_stats":     "Reset Statistics",
+	"menu.back_to_title":   "Back to Title",
+	"menu.score":           "Current Score: %d",
+	"menu.combo":           "Current Combo: %d (Max: %d)",
+	"menu.length":          "Snake Length: %d",
+	"menu.playfield":       "Playfield: %dx%d",
+
+	// Pause and game over
+	"pause.title":          "⏸️ PAUSED",
+	"pause.hint":           "Press %s to Resume or %s for Menu",
+	"game_over.mode":       "Mode: %s",
+	"game_over.score":      "Final Score: %d",
+	"game_over.high_score": "🏆 NEW HIGH SCORE! 🏆",
+	"game_over.rank":       "Ranked #%d on %s",
+	"game_over.hint":       "Press %s/%s to Restart or %s for Menu",
+	"game_over.unlocked":   "🔓 UNLOCKED: %s",
+
+	// HUD
+	"hud.score":          "Score: %d | High: %d | Speed: %d",
+	"hud.length":         "Length: %d | Combo: %dx (Best: %dx)",
+	"hud.arena":          "Arena: %dx%d",
+	"hud.practice":       "(PRACTICE)",
+	"hud.boost":          "🚀 BOOST: %ds",
+	"hud.slow":           "🐌 SLOW: %ds",
+	"hud.shield":         "🛡️ SHIELD: %ds",
+	"hud.powerup.bonus":  "💰 BONUS: %ds",
+	"hud.powerup.speed":  "🚀 SPEED: %ds",
+	"hud.powerup.shield": "🛡️ SHIELD: %ds",
+	"hud.controls":       "%s: Fullscreen | %s: Menu | %s: Pause | %s %s: Speed",
+	"hud.level":          "Level: %d (%s) | Next at %d pts",
+	"hud.level_max":      "Level: %d MAX (%s)",
+	"hud.level_up":       "▲ LEVEL UP!",
+	"hud.time_left":      "⏱ TIME LEFT: %s",
+	"hud.survived":       "Survived: %s | Next speed-up: %ds",
+	"hud.hunger":         "Hunger: %d%%",
+	"hud.scanning":       "Scanning for food...",
+
+	// Run summaries
+	"summary.time_up":      "⏱ TIME UP ⏱",
+	"summary.crashed_with": "💀 CRASHED WITH %s LEFT 💀",
+	"summary.starved":      "💀 STARVED 💀",
+	"summary.failed":       "💀 MISSION FAILED 💀",
+	"summary.food_combo":   "Food eaten: %d | Best combo: %dx",
+	"summary.pace":         "Pace: %.1f points per minute",
+	"summary.survived":     "Survived: %s | Food eaten: %d",
+	"summary.peak":         "Peak length: %d | Final speed: %d",
+	"summary.endless":      "Length: %d | Best combo: %dx | Time: %s",
+	"summary.hit_wall":     "Hit the arena wall",
+	"summary.hit_hazard":   "Hit an asteroid",
+	"summary.level":        "Level reached: %d (%s)",
+
+	// Modes, difficulties, rules and unlocks
+	"mode.endless":         "Endless",
+	"mode.endless.desc":    "The classic: eat, grow and chase combos until you crash.",
+	"mode.timeattack":      "Time Attack",
+	"mode.timeattack.desc": "Score as many points as you can in 120 seconds.",
+	"mode.survival":        "Survival",
+	"mode.survival.desc":   "Food is rare, hunger shrinks you and the pace rises every 30s.",
+	"mode.daily":           "Daily Challenge",
+	"mode.weekly":          "Weekly Challenge",
+	"modes.title":          "=== SELECT MODE ===",
+	"modes.hint":           "UP/DOWN: Select | ENTER: Launch | ESC: Back",
+
+	"difficulty.off":     "Off",
+	"difficulty.casual":  "Casual",
+	"difficulty.classic": "Classic",
+	"difficulty.insane":  "Insane",
+
+	"rules.classic":     "Classic",
+	"rules.walls":       "Solid Walls",
+	"rules.feast":       "Power Feast",
+	"rules.glutton":     "Glutton",
+	"rules.wrap":        "Wrap-around edges",
+	"rules.solid":       "Solid walls",
+	"rules.growth":      "Growth per food: %d",
+	"rules.powerups":    "Power-ups: %d%% (%s)",
+	"rules.no_powerups": "none",
+	"rules.start_speed": "Starting speed: %d",
+
+	"powerup.bonus":  "Bonus",
+	"powerup.speed":  "Speed",
+	"powerup.shield": "Shield",
+
+	"unlock.first_run": "First Flight",
+	"unlock.score_25":  "Star Eater (25 points)",
+	"unlock.score_100": "Supernova (100 points)",
+	"unlock.combo_10":  "Chain Reaction (x10 combo)",
+	"unlock.length_50": "Cosmic Serpent (length 50)",
+
+	// Options
+	"options.title":             "=== OPTIONS ===",
+	"options.language":          "Language",
+	"options.window_mode":       "Window Mode",
+	"options.resolution":        "Resolution",
+	"options.vsync":             "VSync",
+	"options.master_volume":     "Master Volume",
+	"options.music_volume":      "Music Volume",
+	"options.sfx_volume":        "SFX Volume",
+	"options.effects":           "Effects Quality",
+	"options.start_speed":       "Starting Speed",
+	"options.speed_default":     "Default",
+	"options.arena":             "Arena Size",
+	"options.postfx":            "Post-processing: Bloom, CRT and more",
+	"options.controls":          "Controls: Edit key bindings",
+	"options.controllers.one":   "Controllers: %d connected",
+	"options.controllers.other": "Controllers: %d connected",
+	"options.rules":             "Rules",
+	"options.next_run":          "Speed, arena and rules apply from the next run and have their own leaderboards",
+	"options.hint":              "UP/DOWN: Select | LEFT/RIGHT/ENTER: Change | ESC: Back",
+
+	"window.fullscreen": "Fullscreen",
+	"window.windowed":   "Windowed",
+	"window.borderless": "Borderless",
+	"effects.low":       "Low",
+	"effects.medium":    "Medium",
+	"effects.high":      "High",
+	"arena.auto":        "Auto (fit window)",
+	"arena.small":       "Small",
+	"arena.medium":      "Medium",
+	"arena.large":       "Large",
+	"arena.huge":        "Huge",
+	"arena.vast":        "Vast",
+
+	// Accessibility
+	"access.title":          "=== ACCESSIBILITY ===",
+	"access.shapes":         "Item Shapes",
+	"access.menu_scale":     "Menu Scale",
+	"access.hud_scale":      "HUD Scale",
+	"access.shake":          "Screen Shake",
+	"access.flashing":       "Flashing Effects",
+	"access.reduced_motion": "Reduced Motion",
+
+	// Post-processing
+	"postfx.title":     "=== POST-PROCESSING ===",
+	"postfx.bloom":     "Bloom",
+	"postfx.crt":       "CRT Scanlines",
+	"postfx.chromatic": "Chromatic Aberration",
+	"postfx.vignette":  "Vignette",
+	"postfx.hit_flash": "Hit Flash",
+	"postfx.quality":   "Quality",
+	"postfx.note":      "Screen shake and flashing can also be turned off under Accessibility",
+
+	// Key bindings
+	"controls.title":        "=== CONTROLS: %s ===",
+	"controls.action":       "Action",
+	"controls.key":          "Key %d",
+	"controls.unbound":      "(unbound)",
+	"controls.press_key":    "press a key",
+	"controls.cancelled":    "Cancelled",
+	"controls.bound":        "%s bound to %s",
+	"controls.conflict":     "Conflict: %s is bound to more than one action",
+	"controls.conflicts":    "Keys in red are bound to more than one action",
+	"controls.reset":        "Controls reset to defaults",
+	"controls.hint":         "UP/DOWN: Action | LEFT/RIGHT: Slot | ENTER: Rebind | DELETE: Clear | F5: Defaults | ESC: Back",
+	"controls.capture_hint": "Press the new key, or ESC to cancel",
+
+	"action.up":           "Move Up / Menu Up",
+	"action.down":         "Move Down / Menu Down",
+	"action.left":         "Move Left / Previous",
+	"action.right":        "Move Right / Next",
+	"action.confirm":      "Confirm",
+	"action.back":         "Back / Menu",
+	"action.pause":        "Pause",
+	"action.restart":      "Restart",
+	"action.speed_up":     "Speed Up",
+	"action.speed_down":   "Speed Down",
+	"action.fullscreen":   "Fullscreen",
+	"action.leaderboards": "Leaderboards (title)",
+
+	"key.up":    "Up",
+	"key.down":  "Down",
+	"key.left":  "Left",
+	"key.right": "Right",
+
+	// Controllers
+	"pads.title":           "=== CONTROLLERS ===",
+	"pads.connected":       "%s connected as Player %d",
+	"pads.disconnected":    "Player %d controller disconnected",
+	"pads.standard":        "standard",
+	"pads.generic":         "generic",
+	"pads.player":          "Player %d",
+	"pads.player_reserved": "Player %d (reserved)",
+	"pads.deadzone":        "Stick Deadzone",
+	"pads.none":            "No controllers connected - plug one in at any time",
+	"pads.player_one":      "Only Player 1 steers the snake. Players 2-%d are reserved for local multiplayer and only drive the menus",
+	"pads.buttons":         "D-pad/Stick: Move | A: Confirm | B: Back | Start: Pause | Y: Restart | LB/RB: Speed",
+	"pads.hint":            "UP/DOWN: Select | LEFT/RIGHT: Change | ESC: Back",
+
+	// Challenges
+	"challenge.title":              "=== CHALLENGES ===",
+	"challenge.daily_title":        "DAILY CHALLENGE - %s",
+	"challenge.weekly_title":       "WEEKLY CHALLENGE - Week of %s",
+	"challenge.arena":              "Arena: %dx%d",
+	"challenge.not_attempted":      "Not attempted yet - one scored attempt available",
+	"challenge.attempt_used.one":   "Scored attempt used: %d point (replays are practice)",
+	"challenge.attempt_used.other": "Scored attempt used: %d points (replays are practice)",
+	"challenge.attempt_abandoned":  "Scored attempt abandoned (replays are practice)",
+	"challenge.streak":             "Streak: %d (Best: %d)",
+	"challenge.recent":             "Recent Daily Results",
+	"challenge.result":             "%s   Score: %-5d Length: %-4d Combo: %d",
+	"challenge.none_played":        "No daily challenges played yet",
+	"challenge.hint":               "UP/DOWN: Select | ENTER: Play | ESC: Back",
+
+	// Leaderboards
+	"boards.title":            "=== LEADERBOARDS ===",
+	"boards.empty":            "No runs recorded yet - go set a record!",
+	"boards.name":             "NAME",
+	"boards.score":            "SCORE",
+	"boards.length":           "LENGTH",
+	"boards.combo":            "COMBO",
+	"boards.time":             "TIME",
+	"boards.date":             "DATE",
+	"boards.check":            "CHECK",
+	"boards.ok":               "OK",
+	"boards.fail":             "FAIL",
+	"boards.no_replay":        "NO REPLAY",
+	"boards.invalid":          "INVALID",
+	"boards.skipped":          "TOO LONG",
+	"boards.pending":          "...",
+	"boards.verifying":        "Verifying replays...",
+	"boards.flagged.one":      "%d entry does not reproduce from its replay and may have been edited",
+	"boards.flagged.other":    "%d entries do not reproduce from their replay and may have been edited",
+	"boards.unverified.one":   "%d entry has no replay and cannot be verified",
+	"boards.unverified.other": "%d entries have no replay and cannot be verified",
+	"boards.too_long.one":     "%d run is too long to verify here - use snake verify",
+	"boards.too_long.other":   "%d runs are too long to verify here - use snake verify",
+	"boards.hint":             "LEFT/RIGHT: Switch Board | ENTER/ESC: Back",
+	"boards.enter_name":       "LEADERBOARD RANK #%d - ENTER YOUR NAME:",
+	"boards.name_hint":        "Type to edit | BACKSPACE: Delete | ENTER: Save",
+
+	// Profiles
+	"profiles.title":          "=== PROFILES ===",
+	"profiles.active":         "(active)",
+	"profiles.high":           "High: %d",
+	"profiles.games.one":      "%d game",
+	"profiles.games.other":    "%d games",
+	"profiles.unlocks":        "Unlocks: %d/%d",
+	"profiles.selected":       "Now playing as %s",
+	"profiles.default_name":   "Player %d",
+	"profiles.limit":          "Profile limit reached (%d)",
+	"profiles.last":           "The last profile cannot be deleted",
+	"profiles.empty_name":     "Profile name cannot be empty",
+	"profiles.name_taken":     "A profile named %s already exists",
+	"profiles.renamed":        "Renamed to %s",
+	"profiles.created":        "Created %s",
+	"profiles.deleted":        "Deleted %s",
+	"profiles.locked":         "Finish or quit the current run before changing profile",
+	"profiles.new_name":       "NEW PROFILE NAME:",
+	"profiles.rename":         "RENAME PROFILE:",
+	"profiles.edit_hint":      "ENTER: Save | ESC: Cancel",
+	"profiles.new":            "New Profile",
+	"profiles.rename_button":  "Rename %s",
+	"profiles.delete":         "Delete %s",
+	"profiles.confirm_delete": "Delete %s and all of their stats?",
+	"profiles.delete_hint":    "%s: Choose | %s: Cancel",
+	"profiles.hint":           "%s: Select | %s: Back",
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	modeEndless    = "endless"
+	modeTimeAttack = "timeattack"
+	modeSurvival   = "survival"
+
+	timeAttackFrames     = 120 * 60 // 2 minutes at 60fps
+	survivalStarveFrames = 300      // a segment is lost every 5 seconds without food
+	survivalRampFrames   = 30 * 60  // speed increases every 30 seconds
+	survivalFoodDelayMin = 180
+	survivalFoodDelayMax = 480
+)
+
+// Reasons a run can end, shown in the game-over summary
+const (
+	endCrash   = "crash"
+	endWall    = "wall"
+	endStarved = "starved"
+	endTimeUp  = "time"
+	endHazard  = "hazard"
+)
+
+// ==================== GAME MODES ====================
+
+type GameMode struct {
+	ID string
+}
+
+var gameModes = []GameMode{{modeEndless}, {modeTimeAttack}, {modeSurvival}}
+
+// modeName names a mode, or the daily or weekly challenge.
+func modeName(id string) string {
+	return tr("mode." + id)
+}
+
+func (m GameMode) Description() string {
+	return tr("mode." + m.ID + ".desc")
+}
+
+// updateMode runs the per-frame rules of the timed and survival modes. It
+// returns true when the run ended this frame.
+func (g *Game) updateMode() bool {
+	switch g.mode {
+	case modeTimeAttack:
+		if g.frame >= timeAttackFrames {
+			g.endRun(g.snake[0], endTimeUp)
+			return true
+		}
+
+	case modeSurvival:
+		// Food only comes back after a delay
+		if !g.foodActive {
+			g.foodTimer--
+			if g.foodTimer <= 0 {
+				g.placeFood()
+			}
+		}
+
+		// Starvation eats the tail, and eventually the snake
+		g.hunger++
+		if g.hunger >= survivalStarveFrames {
+			g.hunger = 0
+			if g.grow > 0 {
+				g.grow--
+			} else if len(g.snake) > 1 {
+				g.snake = g.snake[:len(g.snake)-1]
+			} else {
+				g.endRun(g.snake[0], endStarved)
+				return true
+			}
+		}
+
+		// The pace ramps up regularly
+		if g.frame%survivalRampFrames == 0 && g.baseSpeed > minSpeed {
+			g.baseSpeed--
+		}
+	}
+	return false
+}
+
+// onFoodEaten decides where the next food comes from after a meal.
+func (g *Game) onFoodEaten() {
+	g.foodEaten++
+	if g.mode == modeSurvival {
+		g.hunger = 0
+		g.foodActive = false
+		g.foodTimer = survivalFoodDelayMin + g.rng.Intn(survivalFoodDelayMax-survivalFoodDelayMin)
+		return
+	}
+	g.placeFood()
+}
+
+// ==================== MODE SUMMARIES ====================
+
+// modeHUDLines are the mode-specific lines shown under the main HUD.
+func (g *Game) modeHUDLines() []string {
+	switch g.mode {
+	case modeTimeAttack:
+		remaining := (timeAttackFrames - g.frame + 59) / 60
+		if remaining < 0 {
+			remaining = 0
+		}
+		return []string{tr("hud.time_left", formatDuration(int64(remaining)))}
+	case modeSurvival:
+		lines := []string{
+			tr("hud.survived", formatDuration(int64(g.frame/60)),
+				(survivalRampFrames-g.frame%survivalRampFrames)/60+1),
+			tr("hud.hunger", g.hunger*100/survivalStarveFrames),
+		}
+		if !g.foodActive {
+			lines = append(lines, tr("hud.scanning"))
+		}
+		return lines
+	}
+	return nil
+}
+
+// runSummary describes how the run ended, per mode.
+func (g *Game) runSummary() (title string, lines []string) {
+	seconds := int64(g.frame / 60)
+	switch g.mode {
+	case modeTimeAttack:
+		title = tr("summary.time_up")
+		if g.endReason != endTimeUp {
+			title = tr("summary.crashed_with", formatDuration(int64((timeAttackFrames-g.frame)/60)))
+		}
+		perMinute := 0.0
+		if g.frame > 0 {
+			perMinute = float64(g.score) / (float64(g.frame) / 3600)
+		}
+		lines = []string{
+			tr("summary.food_combo", g.foodEaten, g.maxCombo),
+			tr("summary.pace", perMinute),
+		}
+	case modeSurvival:
+		title = tr("summary.starved")
+		if g.endReason != endStarved {
+			title = tr("summary.failed")
+		}
+		lines = []string{
+			tr("summary.survived", formatDuration(seconds), g.foodEaten),
+			tr("summary.peak", g.peakLength, maxSpeed-g.baseSpeed+minSpeed),
+		}
+	default:
+		title = tr("summary.failed")
+		lines = []string{
+			tr("summary.endless", len(g.snake), g.maxCombo, formatDuration(seconds)),
+		}
+	}
+	switch g.endReason {
+	case endWall:
+		lines = append(lines, tr("summary.hit_wall"))
+	case endHazard:
+		lines = append(lines, tr("summary.hit_hazard"))
+	}
+	if g.difficulty.progressive() {
+		lines = append(lines, tr("summary.level", g.level, g.difficulty.Title()))
+	}
+	return title, lines
+}
+
+// ==================== MODE SELECT SCREEN ====================
+
+func (g *Game) openModeSelect() {
+	g.modeCursor = 0
+	for i, m := range gameModes {
+		if m.ID == g.selectedMode {
+			g.modeCursor = i
+		}
+	}
+	g.pushScene(&screenScene{update: g.updateModeSelect, draw: g.drawModeSelectScreen})
+}
+
+func (g *Game) updateModeSelect() error {
+	g.updateUI(g.modeSelectView())
+	return nil
+}
+
+func (g *Game) modeSelectView() Widget {
+	items := make([]Widget, len(gameModes))
+	for i, m := range gameModes {
+		items[i] = &Panel{Spacing: 5, Children: []Widget{&Label{Text: modeName(m.ID)}, label(m.Description(), palette.UI.Info)}}
+	}
+	list := &List{Items: items, Cursor: &g.modeCursor, RowHeight: 50, OnSelect: func(i int) {
+		g.selectedMode = gameModes[i].ID
+		g.resetGameplay()
+	}}
+	return &Screen{
+		Backdrop: palette.UI.Overlay,
+		Children: []Widget{&Panel{Anchor: AnchorCenter, Children: []Widget{
+			label(tr("modes.title"), palette.UI.Title),
+			Gap(50),
+			list,
+			Gap(30),
+			label(tr("modes.hint"), palette.UI.Text),
+		}}},
+	}
+}
+
+func (g *Game) drawModeSelectScreen(screen *ebiten.Image) {
+	g.renderUI(screen, g.modeSelectView())
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Most particles alive at once at the highest effects quality; lower
+// qualities get a share of it
+const maxParticles = 2000
+
+// ==================== PARTICLE SYSTEM ====================
+
+// Particle is one live particle. Positions are in arena pixels so particles
+// scroll with the camera; sizes and speeds are scaled to the cell size when
+// the particle is emitted.
+type Particle struct {
+	pos      Vector2
+	vel      Vector2
+	age      float64 // frames lived
+	life     float64 // frames until it disappears
+	start    color.RGBA
+	end      color.RGBA
+	size     float64
+	endSize  float64
+	rotation float64
+	rotVel   float64
+	gravity  float64
+	drag     float64
+}
+
+// ParticleEmitter describes a kind of effect. A burst emits Count particles
+// at once; a continuous emitter used through a particleStream emits Rate
+// particles per frame instead. Ranges are {min, max}.
+type ParticleEmitter struct {
+	Count int
+	Rate  float64
+
+	Direction float64    // centre of the cone, radians, 0 is right and -π/2 up
+	Spread    float64    // width of the cone, radians; 2π for every direction
+	Speed     [2]float64 // pixels per frame at the base cell size
+	Drag      float64    // velocity kept each frame
+	Gravity   float64    // pixels per frame², positive falls
+
+	Life    [2]float64 // seconds
+	Size    [2]float64 // pixels at the base cell size
+	EndSize float64    // size at the end of its life, as a fraction of the start
+	Spin    float64    // fastest rotation, radians per frame, either way
+
+	// Colour over life: the emitted colour moves towards EndColor by EndMix
+	// (0 keeps it, 1 reaches it) as the particle ages, while fading out
+	EndColor color.RGBA
+	EndMix   float64
+}
+
+// Presets for the game's events
+var (
+	eatParticles = ParticleEmitter{
+		Count: 8, Spread: 2 * math.Pi, Speed: [2]float64{2, 6}, Drag: 0.98,
+		Life: [2]float64{0.8, 1.2}, Size: [2]float64{2, 5}, EndSize: 0.5, Spin: 0.15,
+	}
+	comboParticles = ParticleEmitter{
+		Direction: -math.Pi / 2, Spread: math.Pi / 2, Speed: [2]float64{3, 7}, Drag: 0.96, Gravity: 0.12,
+		Life: [2]float64{0.6, 1.0}, Size: [2]float64{2, 4}, EndSize: 0.3, Spin: 0.3,
+		EndColor: color.RGBA{255, 255, 255, 255}, EndMix: 0.7,
+	}
+	deathParticles = ParticleEmitter{
+		Count: 40, Spread: 2 * math.Pi, Speed: [2]float64{1, 8}, Drag: 0.97, Gravity: 0.08,
+		Life: [2]float64{1.0, 1.8}, Size: [2]float64{3, 7}, EndSize: 0.4, Spin: 0.25,
+		EndColor: color.RGBA{60, 60, 60, 255}, EndMix: 0.8,
+	}
+	powerUpParticles = ParticleEmitter{
+		Count: 16, Spread: 2 * math.Pi, Speed: [2]float64{4, 5}, Drag: 0.93,
+		Life: [2]float64{0.5, 0.7}, Size: [2]float64{3, 4}, EndSize: 0.2, Spin: 0.2,
+		EndColor: color.RGBA{255, 255, 255, 255}, EndMix: 1,
+	}
+	sparkleParticles = ParticleEmitter{
+		Rate: 0.1, Direction: -math.Pi / 2, Spread: math.Pi / 3, Speed: [2]float64{0.5, 1.5}, Drag: 0.98,
+		Life: [2]float64{0.8, 1.2}, Size: [2]float64{2, 4}, EndSize: 0.3, Spin: 0.1,
+		EndColor: color.RGBA{255, 255, 255, 255}, EndMix: 0.5,
+	}
+	levelUpParticles = ParticleEmitter{
+		Count: 24, Direction: -math.Pi / 2, Spread: math.Pi, Speed: [2]float64{2, 6}, Drag: 0.97, Gravity: 0.05,
+		Life: [2]float64{1.0, 1.4}, Size: [2]float64{2, 5}, EndSize: 0.5, Spin: 0.2,
+		EndColor: color.RGBA{255, 255, 255, 255}, EndMix: 0.4,
+	}
+)
+
+// particleBudget is how many particles may be alive at the current effects
+// quality. Emitters stop adding particles once it is reached.
+func (g *Game) particleBudget() int {
+	return int(maxParticles * g.effectsScale())
+}
+
+// emit fires a burst of e at the centre of cell pos.
+func (g *Game) emit(e *ParticleEmitter, pos Point, c color.RGBA) {
+	g.emitN(e, pos, e.Count, c)
+}
+
+// emitN fires a burst of n particles of e at the centre of cell pos. Lower
+// effects quality spawns fewer of them.
+func (g *Game) emitN(e *ParticleEmitter, pos Point, n int, c color.RGBA) {
+	if g.particles == nil {
+		g.particles = make([]Particle, 0, maxParticles)
+	}
+	n = int(math.Ceil(float64(n) * g.effectsScale()))
+	if free := g.particleBudget() - len(g.particles); n > free {
+		n = free
+	}
+
+	cell := float64(g.cellSize)
+	x := (float64(pos.X) + 0.5) * cell
+	y := (float64(pos.Y) + 0.5) * cell
+	scale := g.scaleFactor
+	if scale <= 0 {
+		scale = 1 // headless simulations have no cell size
+	}
+
+	for i := 0; i < n; i++ {
+		// Spread evenly through the cone with a little jitter, as before
+		angle := e.Direction - e.Spread/2 + e.Spread*(float64(i)+g.fxRng.Float64()*0.5)/float64(n)
+		speed := between(g.fxRng.Float64(), e.Speed) * scale
+		g.particles = append(g.particles, Particle{
+			pos:      Vector2{x, y},
+			vel:      Vector2{math.Cos(angle) * speed, math.Sin(angle) * speed},
+			life:     between(g.fxRng.Float64(), e.Life) * 60,
+			start:    c,
+			end:      mixRGBA(c, e.EndColor, e.EndMix),
+			size:     between(g.fxRng.Float64(), e.Size) * scale,
+			endSize:  e.EndSize,
+			rotation: g.fxRng.Float64() * 2 * math.Pi,
+			rotVel:   (g.fxRng.Float64()*2 - 1) * e.Spin,
+			gravity:  e.Gravity * scale,
+			drag:     e.Drag,
+		})
+	}
+}
+
+// particleStream feeds a continuous emitter, carrying fractional particles
+// over from frame to frame.
+type particleStream struct {
+	carry float64
+}
+
+// update emits this frame's share of e's Rate at pos.
+func (s *particleStream) update(g *Game, e *ParticleEmitter, pos Point, c color.RGBA) {
+	s.carry += e.Rate
+	if n := int(s.carry); n > 0 {
+		s.carry -= float64(n)
+		g.emitN(e, pos, n, c)
+	}
+}
+
+// updateParticles moves every particle one frame on. Dead particles are
+// replaced by the last live one, so removal never shifts the slice.
+func (g *Game) updateParticles() {
+	for i := 0; i < len(g.particles); {
+		p := &g.particles[i]
+		p.age++
+		if p.age >= p.life {
+			last := len(g.particles) - 1
+			g.particles[i] = g.particles[last]
+			g.particles = g.particles[:last]
+			continue
+		}
+		p.vel.Y += p.gravity
+		p.vel.X *= p.drag
+		p.vel.Y *= p.drag
+		p.pos.X += p.vel.X
+		p.pos.Y += p.vel.Y
+		p.rotation += p.rotVel
+		i++
+	}
+}
+
+// drawParticles draws every particle as a spinning square in one batch.
+func (g *Game) drawParticles(screen *ebiten.Image) {
+	originX, originY := g.arenaOrigin()
+	batch := &g.renderer.batch
+	for _, p := range g.particles {
+		t := p.age / p.life
+		c := mixRGBA(p.start, p.end, t)
+		c.A = uint8(float64(c.A) * (1 - t))
+		size := p.size * (1 + (p.endSize-1)*t)
+
+		x := originX + p.pos.X
+		y := originY + p.pos.Y
+
+		batch.square(screen, x, y, size, p.rotation, c)
+	}
+	batch.flush(screen)
+}
+
+// between maps t in 0..1 onto the range r.
+func between(t float64, r [2]float64) float64 {
+	return r[0] + (r[1]-r[0])*t
+}
+
+// mixRGBA blends a towards b by t.
+func mixRGBA(a, b color.RGBA, t float64) color.RGBA {
+	mix := func(x, y uint8) uint8 {
+		return uint8(float64(x) + (float64(y)-float64(x))*t)
+	}
+	return color.RGBA{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B), mix(a.A, b.A)}
+}
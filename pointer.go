@@ -0,0 +1,179 @@
+package main
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const (
+	swipeDistance    = 30.0 // pixels of drag before a press counts as a swipe
+	pointerIdleTicks = 300  // hide the on-screen back button after this long
+)
+
+// ==================== MOUSE AND TOUCH ====================
+
+// pointerState tracks one mouse or touch gesture at a time. A press that
+// travels less than swipeDistance is a tap on release; otherwise every
+// swipeDistance of travel while held is a swipe, so one drag can steer
+// several turns.
+type pointerState struct {
+	down    bool
+	touch   bool
+	touchID ebiten.TouchID
+	start   Vector2
+	pos     Vector2
+	swiping bool
+
+	hover  Vector2 // last mouse position
+	moved  bool    // the mouse moved this tick
+	idle   int     // ticks since the pointer was last used
+	tapped bool
+	tap    Vector2
+	swipe  Point // direction of this tick's swipe, zero if none
+	back   bool  // right click
+}
+
+// updatePointer turns raw mouse and touch input into taps and swipes. It
+// runs once per tick before any input is read.
+func (g *Game) updatePointer() {
+	p := &g.pointer
+	p.tapped, p.swipe, p.back, p.moved = false, Point{}, false, false
+	p.idle++
+
+	x, y := ebiten.CursorPosition()
+	if cursor := (Vector2{float64(x), float64(y)}); cursor != p.hover {
+		p.hover, p.moved, p.idle = cursor, true, 0
+	}
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) {
+		p.back, p.idle = true, 0
+	}
+
+	if !p.down {
+		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+			p.press(p.hover, false, 0)
+		}
+		for _, id := range inpututil.AppendJustPressedTouchIDs(nil) {
+			tx, ty := ebiten.TouchPosition(id)
+			p.press(Vector2{float64(tx), float64(ty)}, true, id)
+			break
+		}
+		return
+	}
+
+	released := false
+	if p.touch {
+		if inpututil.IsTouchJustReleased(p.touchID) {
+			released = true
+		} else {
+			tx, ty := ebiten.TouchPosition(p.touchID)
+			p.pos = Vector2{float64(tx), float64(ty)}
+		}
+	} else {
+		p.pos = p.hover
+		released = !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft)
+	}
+
+	dx, dy := p.pos.X-p.start.X, p.pos.Y-p.start.Y
+	if math.Hypot(dx, dy) >= swipeDistance {
+		p.swipe = dominantDir(dx, dy)
+		p.swiping = true
+		p.start = p.pos
+	}
+	if released {
+		p.down = false
+		if !p.swiping {
+			p.tapped, p.tap = true, p.pos
+		}
+	}
+	p.idle = 0
+}
+
+func (p *pointerState) press(at Vector2, touch bool, id ebiten.TouchID) {
+	p.down, p.touch, p.touchID = true, touch, id
+	p.start, p.pos = at, at
+	p.swiping = false
+	p.idle = 0
+}
+
+// dominantDir reduces a vector to the grid direction it mostly points in.
+func dominantDir(dx, dy float64) Point {
+	if math.Abs(dx) > math.Abs(dy) {
+		if dx < 0 {
+			return Point{-1, 0}
+		}
+		return Point{1, 0}
+	}
+	if dy < 0 {
+		return Point{0, -1}
+	}
+	return Point{0, 1}
+}
+
+// pointerDirection is the steering requested by the pointer this tick: a
+// swipe, or a tap on the side of the head the snake should turn towards.
+func (g *Game) pointerDirection() (Point, bool) {
+	if g.pointer.swipe != (Point{}) {
+		return g.pointer.swipe, true
+	}
+	if !g.pointer.tapped || len(g.snake) == 0 {
+		return Point{}, false
+	}
+
+	headX, headY := g.cellCenter(g.snake[0])
+	dx := g.pointer.tap.X - headX
+	dy := g.pointer.tap.Y - headY
+	return dominantDir(dx, dy), true
+}
+
+// tapped reports and consumes a tap, so one tap never triggers two things.
+func (g *Game) tapped() (Vector2, bool) {
+	if !g.pointer.tapped {
+		return Vector2{}, false
+	}
+	g.pointer.tapped = false
+	return g.pointer.tap, true
+}
+
+// ==================== BACK BUTTON ====================
+
+// Touch screens have no Escape key, so while the pointer is in use every
+// screen but the title and the arena shows a back button in the corner.
+
+func (g *Game) showBackButton() bool {
+	if g.pointer.idle >= pointerIdleTicks || g.capturing {
+		return false
+	}
+	switch g.scene().(type) {
+	case *titleScene, *playScene:
+		return false
+	}
+	return true
+}
+
+func (g *Game) backButtonView(onTap func(int)) Widget {
+	return &Panel{
+		Anchor:     AnchorTopLeft,
+		Margin:     12,
+		Padding:    8,
+		Background: palette.UI.HUDBackground,
+		Children:   []Widget{&Label{Text: "◄ " + tr("common.back"), Color: palette.UI.Selected, OnTap: onTap}},
+	}
+}
+
+// pointerBack reports a right click, or a tap on the back button.
+func (g *Game) pointerBack() bool {
+	if g.pointer.back {
+		return true
+	}
+	clicked := false
+	if g.showBackButton() {
+		g.updateUI(g.backButtonView(func(int) { clicked = true }))
+	}
+	return clicked
+}
+
+func (g *Game) drawBackButton(screen *ebiten.Image) {
+	g.renderUI(screen, g.backButtonView(nil))
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	shakeDecay     = 0.9 // shake kept each tick
+	hitFlashDecay  = 0.9 // hit flash kept each tick
+	hitFlashAlpha  = 0.6 // strongest hit flash
+	bloomStrength  = 0.9 // how bright the bloom is added back
+	chromaticShift = 2.0 // channel offset in pixels at the reference size
+	vignetteSize   = 256 // vignette texture, scaled to the window
+	scanlineAlpha  = 64  // darkness of each scanline, out of 255
+)
+
+// PostFX switches the effects applied to the finished frame. Quality uses
+// the effects levels and sets how much work the bloom and CRT filter do.
+type PostFX struct {
+	Bloom     bool   `json:"bloom"`
+	CRT       bool   `json:"crt"`
+	Chromatic bool   `json:"chromatic"`
+	Vignette  bool   `json:"vignette"`
+	HitFlash  bool   `json:"hit_flash"`
+	Quality   string `json:"quality"`
+}
+
+var defaultPostFX = PostFX{Bloom: true, Vignette: true, HitFlash: true, Quality: effectsMedium}
+
+// ==================== POST-PROCESSING ====================
+
+// When any effect is on, Draw renders the frame into an offscreen image and
+// the pipeline composites it onto the screen:
+//
+//  1. bloom: glow sources (the head and the food) are drawn into a small
+//     buffer, blurred by halving it a few times and scaling back up, and
+//     added to the frame
+//  2. camera: screen shake moves and slightly zooms the whole frame
+//  3. chromatic aberration: the red and blue channels are drawn offset
+//  4. CRT scanlines, vignette and the hit flash go on top
+//
+// With every effect off and nothing shaking, Draw goes straight to the
+// screen as before.
+
+type glowSource struct {
+	x, y, radius float64
+	color        color.RGBA
+}
+
+type postPipeline struct {
+	frame    *ebiten.Image   // the finished frame before post-processing
+	bloom    []*ebiten.Image // blur chain, each half the size of the one before
+	crt      *ebiten.Image   // scanlines and phosphor mask for the window size
+	crtKey   [3]int          // size and quality crt was built for
+	vignette *ebiten.Image
+	glows    []glowSource // collected while drawing the frame
+}
+
+// postFX is the active profile's post-processing settings.
+func (g *Game) postFX() *PostFX {
+	return &g.playerSettings().PostFX
+}
+
+// updatePostFX fades the shake and the hit flash. It runs every tick,
+// whatever the scene, so neither lingers behind the game-over screen.
+func (g *Game) updatePostFX() {
+	g.shakeIntensity *= shakeDecay
+	if g.shakeIntensity < 0.1 {
+		g.shakeIntensity = 0
+	}
+	g.hitFlash *= hitFlashDecay
+	if g.hitFlash < 0.01 {
+		g.hitFlash = 0
+	}
+}
+
+// flashHit starts the hit flash unless it or flashing effects are turned off.
+func (g *Game) flashHit() {
+	if g.postFX().HitFlash && !g.access().NoFlash {
+		g.hitFlash = 1
+	}
+}
+
+// glow adds a bloom source at screen position x, y for this frame.
+func (g *Game) glow(x, y, radius float64, c color.RGBA) {
+	if g.postFX().Bloom {
+		g.post.glows = append(g.post.glows, glowSource{x, y, radius, c})
+	}
+}
+
+// postActive reports whether this frame needs the offscreen pass.
+func (g *Game) postActive() bool {
+	fx := g.postFX()
+	return fx.Bloom || fx.CRT || fx.Chromatic || fx.Vignette || g.hitFlash > 0 || g.shakeIntensity > 0
+}
+
+// beginPost returns the image to draw the frame into: the screen itself, or
+// the offscreen frame when post-processing is on.
+func (g *Game) beginPost(screen *ebiten.Image) *ebiten.Image {
+	g.post.glows = g.post.glows[:0]
+	if !g.postActive() {
+		return screen
+	}
+	w, h := screen.Bounds().Dx(), screen.Bounds().Dy()
+	if g.post.frame == nil || g.post.frame.Bounds().Dx() != w || g.post.frame.Bounds().Dy() != h {
+		g.post.frame = ebiten.NewImage(w, h)
+		g.post.bloom = nil
+	}
+	g.post.frame.Clear()
+	return g.post.frame
+}
+
+// endPost runs the pipeline on the frame drawn since beginPost and puts the
+// result on screen.
+func (g *Game) endPost(screen, frame *ebiten.Image) {
+	if frame == screen {
+		return
+	}
+	fx := g.postFX()
+	w, h := float64(screen.Bounds().Dx()), float64(screen.Bounds().Dy())
+
+	if fx.Bloom && len(g.post.glows) > 0 {
+		g.drawBloom(frame)
+	}
+
+	// Camera: shake moves the frame and zooms in just enough to hide the edges
+	var camera ebiten.GeoM
+	filter := ebiten.FilterNearest
+	if g.shakeIntensity > 0 {
+		amount := g.shakeIntensity * 0.5
+		zoom := math.Max((w+2*amount)/w, (h+2*amount)/h)
+		camera.Translate(-w/2, -h/2)
+		camera.Scale(zoom, zoom)
+		camera.Translate(w/2+(g.fxRng.Float64()*2-1)*amount, h/2+(g.fxRng.Float64()*2-1)*amount)
+		filter = ebiten.FilterLinear
+	}
+
+	screen.Clear()
+	if fx.Chromatic {
+		// Each channel is added on its own, red and blue pulled apart
+		shift := chromaticShift * g.viewScale()
+		for i, dx := range []float64{-shift, 0, shift} {
+			op := &ebiten.DrawImageOptions{GeoM: camera, Filter: filter, Blend: ebiten.BlendLighter}
+			op.GeoM.Translate(dx, 0)
+			mask := [3]float32{}
+			mask[i] = 1
+			op.ColorScale.Scale(mask[0], mask[1], mask[2], 1)
+			screen.DrawImage(frame, op)
+		}
+	} else {
+		screen.DrawImage(frame, &ebiten.DrawImageOptions{GeoM: camera, Filter: filter})
+	}
+
+	if fx.CRT {
+		screen.DrawImage(g.crtOverlay(int(w), int(h)), nil)
+	}
+	if fx.Vignette {
+		op := &ebiten.DrawImageOptions{Filter: ebiten.FilterLinear}
+		op.GeoM.Scale(w/vignetteSize, h/vignetteSize)
+		screen.DrawImage(g.vignette(), op)
+	}
+	if g.hitFlash > 0 {
+		c := palette.Crash
+		a := uint8(255 * hitFlashAlpha * g.hitFlash)
+		vector.DrawFilledRect(screen, 0, 0, float32(w), float32(h), color.NRGBA{c.R, c.G, c.B, a}, false)
+	}
+}
+
+// ==================== BLOOM ====================
+
+// bloomSetup is the first buffer's downscale and how many times it is
+// halved for each quality.
+func (g *Game) bloomSetup() (downscale, passes int) {
+	switch g.postFX().Quality {
+	case effectsLow:
+		return 8, 1
+	case effectsHigh:
+		return 2, 4
+	}
+	return 4, 3
+}
+
+func (g *Game) drawBloom(frame *ebiten.Image) {
+	down, passes := g.bloomSetup()
+	if len(g.post.bloom) != passes+1 {
+		g.post.bloom = nil
+		w, h := frame.Bounds().Dx()/down, frame.Bounds().Dy()/down
+		for i := 0; i <= passes; i++ {
+			g.post.bloom = append(g.post.bloom, ebiten.NewImage(w+1, h+1))
+			w, h = w/2, h/2
+		}
+	}
+	chain := g.post.bloom
+
+	// The sources, drawn small; the blur below does the softening
+	chain[0].Clear()
+	scale := 1 / float64(down)
+	for _, s := range g.post.glows {
+		vector.DrawFilledCircle(chain[0], float32(s.x*scale), float32(s.y*scale), float32(s.radius*scale), s.color, true)
+	}
+
+	// Halve down the chain, then add each level back into the one above. The
+	// sharp first level is left out so only the blur reaches the frame.
+	for i := 1; i < len(chain); i++ {
+		chain[i].Clear()
+		op := &ebiten.DrawImageOptions{Filter: ebiten.FilterLinear}
+		op.GeoM.Scale(0.5, 0.5)
+		chain[i].DrawImage(chain[i-1], op)
+	}
+	for i := len(chain) - 1; i > 1; i-- {
+		op := &ebiten.DrawImageOptions{Filter: ebiten.FilterLinear, Blend: ebiten.BlendLighter}
+		op.GeoM.Scale(2, 2)
+		chain[i-1].DrawImage(chain[i], op)
+	}
+
+	op := &ebiten.DrawImageOptions{Filter: ebiten.FilterLinear, Blend: ebiten.BlendLighter}
+	op.GeoM.Scale(float64(down*2), float64(down*2))
+	op.ColorScale.ScaleAlpha(bloomStrength)
+	frame.DrawImage(chain[1], op)
+}
+
+// ==================== CRT AND VIGNETTE ====================
+
+// crtOverlay is the scanline pattern for a w by h window, rebuilt when the
+// size or quality changes. High quality adds a faint RGB phosphor mask.
+func (g *Game) crtOverlay(w, h int) *ebiten.Image {
+	high := 0
+	if g.postFX().Quality == effectsHigh {
+		high = 1
+	}
+	key := [3]int{w, h, high}
+	if g.post.crt != nil && g.post.crtKey == key {
+		return g.post.crt
+	}
+
+	img := ebiten.NewImage(w, h)
+	pitch := math.Max(2, math.Round(3*g.viewScale()))
+	line := color.RGBA{0, 0, 0, scanlineAlpha}
+	var batch quadBatch
+	for y := 0.0; y < float64(h); y += pitch {
+		batch.rect(img, 0, y, float64(w), math.Max(1, pitch/3), line)
+	}
+	if high == 1 {
+		// Zero alpha, so the tint is added rather than covering the frame
+		phosphor := []color.RGBA{{12, 0, 0, 0}, {0, 12, 0, 0}, {0, 0, 12, 0}}
+		for x := 0; x < w; x++ {
+			batch.rect(img, float64(x), 0, 1, float64(h), phosphor[x%3])
+		}
+	}
+	batch.flush(img)
+
+	g.post.crt = img
+	g.post.crtKey = key
+	return img
+}
+
+// vignette is a radial gradient darkening the corners, scaled to the window.
+func (g *Game) vignette() *ebiten.Image {
+	if g.post.vignette != nil {
+		return g.post.vignette
+	}
+	pixels := make([]byte, vignetteSize*vignetteSize*4)
+	for y := 0; y < vignetteSize; y++ {
+		for x := 0; x < vignetteSize; x++ {
+			dx := (float64(x)+0.5)/vignetteSize*2 - 1
+			dy := (float64(y)+0.5)/vignetteSize*2 - 1
+			t := math.Min(1, math.Max(0, (math.Hypot(dx, dy)-0.55)/0.85))
+			// Black, so the premultiplied colour stays zero and only alpha is set
+			pixels[(y*vignetteSize+x)*4+3] = uint8(255 * 0.7 * t * t * (3 - 2*t))
+		}
+	}
+	g.post.vignette = ebiten.NewImage(vignetteSize, vignetteSize)
+	g.post.vignette.WritePixels(pixels)
+	return g.post.vignette
+}
+
+// ==================== POST-PROCESSING SCREEN ====================
+
+func (g *Game) postFXRows() []Widget {
+	fx := g.postFX()
+	return []Widget{
+		&Toggle{Label: tr("postfx.bloom"), On: fx.Bloom, OnChange: func() { fx.Bloom = !fx.Bloom }},
+		&Toggle{Label: tr("postfx.crt"), On: fx.CRT, OnChange: func() { fx.CRT = !fx.CRT }},
+		&Toggle{Label: tr("postfx.chromatic"), On: fx.Chromatic, OnChange: func() { fx.Chromatic = !fx.Chromatic }},
+		&Toggle{Label: tr("postfx.vignette"), On: fx.Vignette, OnChange: func() { fx.Vignette = !fx.Vignette }},
+		&Toggle{Label: tr("postfx.hit_flash"), On: fx.HitFlash, OnChange: func() { fx.HitFlash = !fx.HitFlash }},
+		&Choice{Label: tr("postfx.quality"), Value: tr("effects." + fx.Quality), OnChange: func(d int) {
+			fx.Quality = cycleString(effectsLevels, fx.Quality, d)
+		}},
+	}
+}
+
+func (g *Game) openPostFX() {
+	g.postFXCursor = 0
+	g.pushScene(&screenScene{update: g.updatePostFXScreen, draw: g.drawPostFXScreen})
+}
+
+func (g *Game) postFXView() Widget {
+	list := &List{Items: g.postFXRows(), Cursor: &g.postFXCursor, RowHeight: 30, OnChange: g.saveGameData}
+	return g.optionScreen(tr("postfx.title"), list, tr("postfx.note"), tr("options.hint"))
+}
+
+func (g *Game) updatePostFXScreen() error {
+	g.updateUI(g.postFXView())
+	return nil
+}
+
+func (g *Game) drawPostFXScreen(screen *ebiten.Image) {
+	g.renderUI(screen, g.postFXView())
+}
@@ -0,0 +1,370 @@
+package main
+
+import (
+	"encoding/json"
+	"image/color"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const (
+	maxProfiles        = 8
+	defaultProfileName = "Player 1"
+)
+
+// ==================== PROFILE TYPES ====================
+
+// Profile is everything that belongs to one person playing on this machine.
+type Profile struct {
+	Name    string   `json:"name"`
+	Data    GameData `json:"data"`
+	Unlocks []string `json:"unlocks"`
+
+	Challenges []ChallengeResult `json:"challenges"`
+	Difficulty string            `json:"difficulty"`
+	Skin       string            `json:"skin"`
+	Theme      string            `json:"theme"`
+
+	Access   Accessibility   `json:"accessibility"`
+	Bindings Bindings        `json:"bindings,omitempty"` // only actions the player rebound
+	Settings *PlayerSettings `json:"settings,omitempty"` // nil until migrated, see migratePlayerSettings
+}
+
+// ProfileStore is the on-disk layout of saveFile.
+type ProfileStore struct {
+	Active       int          `json:"active"`
+	Profiles     []*Profile   `json:"profiles"`
+	Leaderboards Leaderboards `json:"leaderboards"`
+}
+
+type profileEditMode int
+
+const (
+	profileEditNone profileEditMode = iota
+	profileEditCreate
+	profileEditRename
+	profileEditDelete
+)
+
+// Unlockable is a milestone a profile earns once and keeps forever.
+type Unlockable struct {
+	ID     string
+	earned func(g *Game) bool
+}
+
+var unlockables = []Unlockable{
+	{"first_run", func(g *Game) bool { return true }},
+	{"score_25", func(g *Game) bool { return g.score >= 25 }},
+	{"score_100", func(g *Game) bool { return g.score >= 100 }},
+	{"combo_10", func(g *Game) bool { return g.maxCombo >= 10 }},
+	{"length_50", func(g *Game) bool { return len(g.snake) >= 50 }},
+}
+
+func (u Unlockable) Title() string {
+	return tr("unlock." + u.ID)
+}
+
+// ==================== PROFILE LOGIC ====================
+
+// migrateLegacy turns a save written before profiles existed into a single
+// default profile, keeping its statistics and any leaderboards.
+func (s *ProfileStore) migrateLegacy(data []byte) {
+	var legacy struct {
+		GameData
+		Leaderboards Leaderboards `json:"leaderboards"`
+	}
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return
+	}
+	s.Active = 0
+	s.Profiles = []*Profile{{Name: defaultProfileName, Data: legacy.GameData}}
+	s.Leaderboards = legacy.Leaderboards
+}
+
+// migratePlayerSettings gives profiles saved before options were kept per
+// player the options this machine had, and checks the ones they already have.
+func (g *Game) migratePlayerSettings() {
+	legacy, migrated := legacyPlayerSettings(), false
+	for _, p := range g.profiles.Profiles {
+		if p.Settings == nil {
+			s := legacy
+			p.Settings = &s
+			migrated = true
+		}
+		p.Settings.validate()
+	}
+	if migrated {
+		g.saveGameData()
+	}
+}
+
+func (s *ProfileStore) nameTaken(name string, except *Profile) bool {
+	for _, p := range s.Profiles {
+		if p != except && strings.EqualFold(p.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Game) selectProfile(index int) {
+	if index < 0 || index >= len(g.profiles.Profiles) {
+		index = 0
+	}
+	g.profiles.Active = index
+	g.profile = g.profiles.Profiles[index]
+	g.gameData = &g.profile.Data
+	g.applyTheme()
+}
+
+// profileLocked reports, with a message, that the active profile cannot
+// change because a run is in progress: its stats and unlocks belong to the
+// profile that started it.
+func (g *Game) profileLocked() bool {
+	if g.runActive() {
+		g.profileMessage = tr("profiles.locked")
+		return true
+	}
+	return false
+}
+
+func (p *Profile) hasUnlock(id string) bool {
+	for _, u := range p.Unlocks {
+		if u == id {
+			return true
+		}
+	}
+	return false
+}
+
+// checkUnlocks grants any milestones reached by the run that just ended and
+// returns the names of the new ones.
+func (g *Game) checkUnlocks() []string {
+	var earned []string
+	for _, u := range unlockables {
+		if !g.profile.hasUnlock(u.ID) && u.earned(g) {
+			g.profile.Unlocks = append(g.profile.Unlocks, u.ID)
+			earned = append(earned, u.Title())
+		}
+	}
+	if len(earned) > 0 {
+		g.saveGameData()
+	}
+	return earned
+}
+
+// ==================== PROFILES SCREEN ====================
+
+func (g *Game) openProfiles() {
+	g.profileCursor = g.profiles.Active
+	g.profileTarget = g.profiles.Active
+	g.profileEdit = profileEditNone
+	g.profileMessage = ""
+	g.pushScene(&screenScene{
+		update: g.updateProfiles,
+		draw:   g.drawProfilesScreen,
+		back:   g.cancelProfileEdit,
+		exit:   func() { g.profileEdit = profileEditNone },
+	})
+}
+
+// cancelProfileEdit backs out of a create, rename or delete prompt. It
+// returns false when there was nothing to cancel.
+func (g *Game) cancelProfileEdit() bool {
+	if g.profileEdit == profileEditNone {
+		return false
+	}
+	g.profileEdit = profileEditNone
+	return true
+}
+
+func (g *Game) updateProfiles() error {
+	if g.typing() {
+		g.nameBuffer = updateTextInput(g.nameBuffer)
+		// Controllers and touch screens cannot type, so confirm or a tap
+		// saves the name as is
+		_, tapped := g.tapped()
+		if inpututil.IsKeyJustPressed(ebiten.KeyEnter) || g.padPressed(ActionConfirm) || tapped {
+			g.commitProfileName()
+		}
+		return nil
+	}
+
+	// The profile rows, the buttons and the delete prompt are all widgets
+	g.updateUI(g.profilesView())
+
+	// Rename and Delete act on the last profile the cursor was on
+	if g.profileCursor < len(g.profiles.Profiles) {
+		g.profileTarget = g.profileCursor
+	}
+	return nil
+}
+
+func (g *Game) startCreateProfile() {
+	count := len(g.profiles.Profiles)
+	if count >= maxProfiles {
+		g.profileMessage = tr("profiles.limit", maxProfiles)
+		return
+	}
+	g.profileEdit = profileEditCreate
+	g.nameBuffer = []rune(tr("profiles.default_name", count+1))
+}
+
+func (g *Game) startRenameProfile() {
+	g.profileEdit = profileEditRename
+	g.nameBuffer = []rune(g.profiles.Profiles[g.profileTarget].Name)
+}
+
+func (g *Game) startDeleteProfile() {
+	if len(g.profiles.Profiles) <= 1 {
+		g.profileMessage = tr("profiles.last")
+		return
+	}
+	g.profileEdit = profileEditDelete
+	g.profileConfirmCursor = 1 // Cancel
+}
+
+func (g *Game) commitProfileName() {
+	name := strings.TrimSpace(string(g.nameBuffer))
+	if name == "" {
+		g.profileMessage = tr("profiles.empty_name")
+		return
+	}
+
+	var target *Profile
+	if g.profileEdit == profileEditRename {
+		target = g.profiles.Profiles[g.profileTarget]
+	}
+	if g.profiles.nameTaken(name, target) {
+		g.profileMessage = tr("profiles.name_taken", name)
+		return
+	}
+
+	if target != nil {
+		target.Name = name
+		g.profileMessage = tr("profiles.renamed", name)
+	} else {
+		settings := defaultPlayerSettings
+		g.profiles.Profiles = append(g.profiles.Profiles, &Profile{Name: name, Settings: &settings})
+		g.profileCursor = len(g.profiles.Profiles) - 1
+		g.profileTarget = g.profileCursor
+		g.profileMessage = tr("profiles.created", name)
+		if !g.runActive() {
+			g.selectProfile(g.profileCursor)
+		}
+	}
+	g.profileEdit = profileEditNone
+	g.saveGameData()
+}
+
+func (g *Game) deleteProfile(index int) {
+	if len(g.profiles.Profiles) <= 1 {
+		return
+	}
+	removed := g.profiles.Profiles[index]
+	active := g.profile
+	if removed == active && g.profileLocked() {
+		return
+	}
+	g.profiles.Profiles = append(g.profiles.Profiles[:index], g.profiles.Profiles[index+1:]...)
+
+	// Keep the same person active unless they were the one deleted
+	newActive := 0
+	for i, p := range g.profiles.Profiles {
+		if p == active {
+			newActive = i
+		}
+	}
+	g.selectProfile(newActive)
+	if index >= len(g.profiles.Profiles) {
+		index = len(g.profiles.Profiles) - 1
+	}
+	g.profileTarget, g.profileCursor = index, index
+	g.profileMessage = tr("profiles.deleted", removed.Name)
+	g.saveGameData()
+}
+
+func (g *Game) profilesView() Widget {
+	children := []Widget{label(tr("profiles.title"), palette.UI.Title), Gap(24)}
+	if g.profileTarget >= len(g.profiles.Profiles) {
+		g.profileTarget = 0
+	}
+	target := g.profiles.Profiles[g.profileTarget]
+
+	// Deleting asks first, with Cancel picked to start with
+	if g.profileEdit == profileEditDelete {
+		confirm := &List{Cursor: &g.profileConfirmCursor, RowHeight: 30, Items: []Widget{
+			&Button{Label: tr("profiles.delete", target.Name), OnClick: func() {
+				g.profileEdit = profileEditNone
+				g.deleteProfile(g.profileTarget)
+			}},
+			&Button{Label: tr("common.cancel"), OnClick: func() { g.profileEdit = profileEditNone }},
+		}}
+		children = append(children,
+			label(tr("profiles.confirm_delete", target.Name), palette.UI.Danger),
+			Gap(12),
+			confirm,
+			Gap(12),
+			label(tr("profiles.delete_hint", g.keyHint(ActionConfirm), g.keyHint(ActionBack)), palette.UI.Text))
+		return &Screen{
+			Backdrop: palette.UI.Overlay,
+			Children: []Widget{&Panel{Anchor: AnchorCenter, Spacing: 11, Children: children}},
+		}
+	}
+
+	rows := make([]Widget, 0, len(g.profiles.Profiles)+3)
+	for i, p := range g.profiles.Profiles {
+		active := ""
+		if i == g.profiles.Active {
+			active = tr("profiles.active")
+		}
+		rows = append(rows, &Row{Widths: []float64{100, 90, 80, 100, 60}, Spacing: 8, Cells: []Widget{
+			&Label{Text: p.Name, Align: AnchorLeft},
+			&Label{Text: tr("profiles.high", p.Data.HighScore), Align: AnchorLeft},
+			&Label{Text: trn("profiles.games", p.Data.TotalGames), Align: AnchorLeft},
+			&Label{Text: tr("profiles.unlocks", len(p.Unlocks), len(unlockables)), Align: AnchorLeft},
+			&Label{Text: active, Align: AnchorLeft},
+		}})
+	}
+	rows = append(rows,
+		&Button{Label: tr("profiles.new"), OnClick: g.startCreateProfile},
+		&Button{Label: tr("profiles.rename_button", target.Name), OnClick: g.startRenameProfile},
+		&Button{Label: tr("profiles.delete", target.Name), OnClick: g.startDeleteProfile},
+	)
+	list := &List{Items: rows, Cursor: &g.profileCursor, RowHeight: 24, OnSelect: func(i int) {
+		if i != g.profiles.Active && g.profileLocked() {
+			return
+		}
+		g.selectProfile(i)
+		g.saveGameData()
+		g.profileMessage = tr("profiles.selected", g.profile.Name)
+	}}
+
+	children = append(children, list, Gap(24))
+	switch g.profileEdit {
+	case profileEditCreate, profileEditRename:
+		prompt := tr("profiles.new_name")
+		if g.profileEdit == profileEditRename {
+			prompt = tr("profiles.rename")
+		}
+		children = append(children,
+			label(prompt, palette.UI.Highlight),
+			label("[ "+string(g.nameBuffer)+"_ ]", color.White),
+			label(tr("profiles.edit_hint"), palette.UI.Text))
+	default:
+		children = append(children,
+			label(g.profileMessage, palette.UI.Accent),
+			Gap(11),
+			label(tr("profiles.hint", g.keyHint(ActionConfirm), g.keyHint(ActionBack)), palette.UI.Text))
+	}
+	return &Screen{
+		Backdrop: palette.UI.Overlay,
+		Children: []Widget{&Panel{Anchor: AnchorCenter, Spacing: 11, Children: children}},
+	}
+}
+
+func (g *Game) drawProfilesScreen(screen *ebiten.Image) {
+	g.renderUI(screen, g.profilesView())
+}
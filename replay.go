@@ -0,0 +1,307 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+)
+
+const (
+	// Frames simulated past a replay's recorded end before giving up on it
+	replayFrameMargin = 60
+
+	// Longest replay that is simulated at all: eight hours at 60 ticks per
+	// second, so a tampered frame count cannot stall `snake verify`
+	maxReplayFrames = 8 * 60 * 60 * 60
+
+	// The Leaderboards screen only checks runs of up to half an hour and
+	// simulates at most verifyStepsPerTick frames per tick, so it stays
+	// responsive while the checks run
+	maxInGameReplayFrames = 30 * 60 * 60
+	verifyStepsPerTick    = 4000
+)
+
+// ==================== REPLAY RECORDING ====================
+
+// ReplayEvent is one input change, applied before the simulation step of
+// Frame. A non-zero Speed is a speed change, otherwise DX/DY is the new
+// queued direction.
+type ReplayEvent struct {
+	Frame int `json:"f"`
+	DX    int `json:"dx,omitempty"`
+	DY    int `json:"dy,omitempty"`
+	Speed int `json:"s,omitempty"`
+}
+
+// Replay is everything besides the seed and arena size needed to reproduce
+// a run frame by frame.
+type Replay struct {
+	Events []ReplayEvent `json:"events"`
+	Frames int           `json:"frames"`
+}
+
+func (r *Replay) record(e ReplayEvent) {
+	if r == nil {
+		return
+	}
+	r.Events = append(r.Events, e)
+}
+
+// ==================== VERIFICATION ====================
+
+type verifyStatus int
+
+const (
+	verifyNoReplay verifyStatus = iota
+	verifyOK
+	verifyMismatch
+	verifyInvalid
+	verifySkipped // too long to check in-game
+	verifyPending // still being simulated
+)
+
+func (s verifyStatus) String() string {
+	switch s {
+	case verifyOK:
+		return "OK"
+	case verifyMismatch:
+		return "MISMATCH"
+	case verifyInvalid:
+		return "INVALID"
+	case verifySkipped:
+		return "SKIPPED"
+	case verifyPending:
+		return "PENDING"
+	}
+	return "NO REPLAY"
+}
+
+// failed reports whether an entry cannot be trusted: its replay gives a
+// different result, holds input the game cannot produce, is missing, or was
+// too long to check. Pending entries are not judged yet.
+func (s verifyStatus) failed() bool {
+	return s != verifyOK && s != verifyPending
+}
+
+type VerifyResult struct {
+	Status   verifyStatus
+	Score    int
+	Length   int
+	MaxCombo int
+	Frames   int
+}
+
+// valid reports whether an event is input the game itself could have
+// recorded while moving in dir: a speed within the Options range, or a
+// single orthogonal step that does not turn straight back.
+func (e ReplayEvent) valid(dir Point) bool {
+	if e.Speed != 0 {
+		return e.Speed >= minSpeed && e.Speed <= maxSpeed
+	}
+	d := Point{e.DX, e.DY}
+	if d.X*d.X+d.Y*d.Y != 1 {
+		return false
+	}
+	return d != Point{-dir.X, -dir.Y}
+}
+
+// replayCheck re-simulates one leaderboard entry headlessly on a bare Game
+// with no audio, renderer or window. It can run in slices, so the
+// Leaderboards screen spreads the work over several ticks.
+type replayCheck struct {
+	entry  LeaderboardEntry
+	g      *Game
+	next   int // next replay event to apply
+	done   bool
+	result VerifyResult
+}
+
+// newReplayCheck prepares to verify e. Replays longer than maxFrames are not
+// simulated: they count as a mismatch beyond maxReplayFrames, and as skipped
+// below it, for `snake verify` to check instead.
+func newReplayCheck(key BoardKey, e LeaderboardEntry, maxFrames int) *replayCheck {
+	c := &replayCheck{entry: e}
+	switch {
+	case e.Replay == nil || key.ArenaW <= 0 || key.ArenaH <= 0:
+		c.finish(verifyNoReplay)
+	case e.Replay.Frames < 0 || e.Replay.Frames > maxReplayFrames:
+		c.finish(verifyMismatch)
+	case e.Replay.Frames > maxFrames:
+		c.finish(verifySkipped)
+	default:
+		c.g = &Game{
+			gridW: key.ArenaW,
+			gridH: key.ArenaH,
+			mode:  key.Mode,
+			rules: rulesForKey(key),
+			fxRng: rand.New(rand.NewSource(e.Seed)),
+		}
+		c.g.startRun(e.Seed)
+	}
+	return c
+}
+
+// advance simulates up to steps more frames and returns how many of them it
+// did not need. The simulation stops at the end of the run, or replayFrameMargin
+// frames after the replay's recorded end if the run never ends there. It
+// gives up at the first event the game could not have recorded, so a forged
+// speed or move is never simulated.
+func (c *replayCheck) advance(steps int) int {
+	for ; !c.done && steps > 0; steps-- {
+		g, replay := c.g, c.entry.Replay
+		if g.over || g.frame > replay.Frames+replayFrameMargin {
+			c.finish(verifyMismatch)
+			break
+		}
+		for c.next < len(replay.Events) && replay.Events[c.next].Frame == g.frame {
+			e := replay.Events[c.next]
+			if !e.valid(g.dir) {
+				c.finish(verifyInvalid)
+				return steps
+			}
+			if e.Speed != 0 {
+				g.baseSpeed = e.Speed
+			} else {
+				g.nextDir = Point{e.DX, e.DY}
+			}
+			c.next++
+		}
+		g.step()
+	}
+	return steps
+}
+
+// finish records the outcome. A finished simulation that matches the entry
+// turns a mismatch into OK.
+func (c *replayCheck) finish(status verifyStatus) {
+	c.done = true
+	c.result = VerifyResult{Status: status}
+	g, e := c.g, c.entry
+	if g == nil {
+		return
+	}
+	c.result.Score, c.result.Length, c.result.MaxCombo, c.result.Frames = g.score, len(g.snake), g.maxCombo, g.frame
+	if status == verifyMismatch && g.over && g.frame == e.Replay.Frames &&
+		g.score == e.Score && len(g.snake) == e.Length && g.maxCombo == e.MaxCombo {
+		c.result.Status = verifyOK
+	}
+}
+
+// verifyEntry checks one entry in a single call, simulating replays of up to
+// maxFrames frames.
+func verifyEntry(key BoardKey, e LeaderboardEntry, maxFrames int) VerifyResult {
+	c := newReplayCheck(key, e, maxFrames)
+	for !c.done {
+		c.advance(verifyStepsPerTick)
+	}
+	return c.result
+}
+
+// boardCheck verifies the entries of one board in order, verifyStepsPerTick
+// frames at a time.
+type boardCheck struct {
+	name    string
+	checks  []*replayCheck
+	results []VerifyResult
+	next    int // entry being simulated
+}
+
+// verifyBoard returns the checks for every entry on a board, with the ones
+// still being simulated marked pending, and whether they are all done.
+// Finished results are cached for the session until a new entry goes on the
+// board, so reopening the screen or switching between boards does not
+// simulate the runs again.
+func (g *Game) verifyBoard(name string) ([]VerifyResult, bool) {
+	if results, ok := g.verifyCache[name]; ok {
+		return results, true
+	}
+	if g.verifying == nil || g.verifying.name != name {
+		board := g.profiles.Leaderboards[name]
+		job := &boardCheck{name: name, results: make([]VerifyResult, len(board.Entries))}
+		for i, e := range board.Entries {
+			job.checks = append(job.checks, newReplayCheck(board.Key, e, maxInGameReplayFrames))
+			job.results[i] = VerifyResult{Status: verifyPending}
+		}
+		g.verifying = job
+	}
+	return g.verifying.results, false
+}
+
+// updateVerification moves the board check along by one tick's worth of
+// simulation and caches its results once every entry is done.
+func (g *Game) updateVerification() {
+	job := g.verifying
+	if job == nil {
+		return
+	}
+	for budget := verifyStepsPerTick; job.next < len(job.checks); job.next++ {
+		c := job.checks[job.next]
+		if budget = c.advance(budget); !c.done {
+			return
+		}
+		job.results[job.next] = c.result
+		c.g = nil // let the simulated game go
+	}
+	if g.verifyCache == nil {
+		g.verifyCache = make(map[string][]VerifyResult)
+	}
+	g.verifyCache[job.name] = job.results
+	g.verifying = nil
+}
+
+// forgetVerification drops the checks of a board whose entries changed.
+func (g *Game) forgetVerification(name string) {
+	delete(g.verifyCache, name)
+	if g.verifying != nil && g.verifying.name == name {
+		g.verifying = nil
+	}
+}
+
+// ==================== VERIFY COMMAND ====================
+
+// runVerifyCommand implements `snake verify`: it re-simulates every
+// leaderboard entry in the save file and reports the ones that do not
+// reproduce. The exit code is non-zero if any entry fails or has no replay.
+func runVerifyCommand(args []string) int {
+	path := saveFile
+	if len(args) > 0 {
+		path = args[0]
+	}
+	if _, err := os.Stat(path); err != nil {
+		fmt.Fprintf(os.Stderr, "verify: %v\n", err)
+		return 2
+	}
+
+	g := &Game{}
+	g.loadGameDataFrom(path)
+
+	checked, failed, unverifiable := 0, 0, 0
+	for _, name := range g.profiles.Leaderboards.boardNames() {
+		board := g.profiles.Leaderboards[name]
+		fmt.Printf("%s\n", board.Key.Title())
+		for i, e := range board.Entries {
+			r := verifyEntry(board.Key, e, maxReplayFrames)
+			checked++
+			fmt.Printf("  %2d. %-12s %6d  %s", i+1, e.Name, e.Score, r.Status)
+			switch r.Status {
+			case verifyNoReplay:
+				unverifiable++
+			case verifyMismatch:
+				failed++
+				fmt.Printf(" (replay gives score %d, length %d, combo %d after %d frames)",
+					r.Score, r.Length, r.MaxCombo, r.Frames)
+			case verifyInvalid:
+				failed++
+				fmt.Printf(" (replay has input the game cannot produce at frame %d)", r.Frames)
+			}
+			fmt.Println()
+		}
+	}
+
+	fmt.Printf("\n%d entries checked: %d ok, %d failed, %d without replay\n",
+		checked, checked-failed-unverifiable, failed, unverifiable)
+	if failed > 0 || unverifiable > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"encoding/json"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// recordRun plays a run the way updateGameplay does, recording each input
+// before the step it applies to, and returns its leaderboard entry. The
+// snake chases the food for a while, speeds up once, then runs straight on
+// until it crashes.
+func recordRun(t *testing.T, key BoardKey, seed int64) LeaderboardEntry {
+	t.Helper()
+	g := &Game{
+		gridW: key.ArenaW,
+		gridH: key.ArenaH,
+		mode:  key.Mode,
+		rules: rulesForKey(key),
+		fxRng: rand.New(rand.NewSource(seed)),
+	}
+	g.startRun(seed)
+
+	for !g.over {
+		if g.frame > maxReplayFrames {
+			t.Fatal("run never ended")
+		}
+		if g.frame == 500 {
+			g.baseSpeed = 6
+			g.replay.record(ReplayEvent{Frame: g.frame, Speed: g.baseSpeed})
+		}
+		if d := chaseFood(g); g.frame < 3000 && d != g.nextDir {
+			g.nextDir = d
+			g.replay.record(ReplayEvent{Frame: g.frame, DX: d.X, DY: d.Y})
+		}
+		g.step()
+	}
+
+	return LeaderboardEntry{
+		Name:     "tester",
+		Score:    g.score,
+		Length:   len(g.snake),
+		MaxCombo: g.maxCombo,
+		Seed:     seed,
+		Replay:   g.replay,
+	}
+}
+
+// chaseFood heads for the food, never straight back on itself.
+func chaseFood(g *Game) Point {
+	head := g.snake[0]
+	d := Point{sign(g.food.X - head.X), 0}
+	if d.X == 0 {
+		d = Point{0, sign(g.food.Y - head.Y)}
+	}
+	if d == (Point{}) || d == (Point{-g.dir.X, -g.dir.Y}) {
+		d = Point{g.dir.Y, g.dir.X}
+	}
+	return d
+}
+
+func sign(n int) int {
+	switch {
+	case n > 0:
+		return 1
+	case n < 0:
+		return -1
+	}
+	return 0
+}
+
+// Solid walls make the scripted run end by itself
+var testKey = BoardKey{Mode: modeEndless, Rules: "walls", ArenaW: 30, ArenaH: 20}
+
+func TestReplayRoundTrip(t *testing.T) {
+	for _, seed := range []int64{1, 42, 20240314} {
+		e := recordRun(t, testKey, seed)
+
+		// Through the save file and back
+		data, err := json.Marshal(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var loaded LeaderboardEntry
+		if err := json.Unmarshal(data, &loaded); err != nil {
+			t.Fatal(err)
+		}
+
+		if r := verifyEntry(testKey, loaded, maxReplayFrames); r.Status != verifyOK {
+			t.Errorf("seed %d: got %v (score %d, length %d, combo %d, %d frames), want OK",
+				seed, r.Status, r.Score, r.Length, r.MaxCombo, r.Frames)
+		}
+	}
+}
+
+func TestReplayDetectsEdits(t *testing.T) {
+	e := recordRun(t, testKey, 7)
+
+	// forge puts an input the game could never record at the start of the
+	// replay, while the snake is still heading right
+	forge := func(ev ReplayEvent) func(e *LeaderboardEntry) {
+		return func(e *LeaderboardEntry) {
+			e.Replay.Events = append([]ReplayEvent{ev}, e.Replay.Events...)
+		}
+	}
+
+	tests := []struct {
+		name string
+		edit func(e *LeaderboardEntry)
+		want verifyStatus
+	}{
+		{"score", func(e *LeaderboardEntry) { e.Score += 10 }, verifyMismatch},
+		{"length", func(e *LeaderboardEntry) { e.Length++ }, verifyMismatch},
+		{"seed", func(e *LeaderboardEntry) { e.Seed++ }, verifyMismatch},
+		{"frames", func(e *LeaderboardEntry) { e.Replay.Frames = maxReplayFrames + 1 }, verifyMismatch},
+		{"no replay", func(e *LeaderboardEntry) { e.Replay = nil }, verifyNoReplay},
+		{"speed too fast", forge(ReplayEvent{Frame: 0, Speed: 1}), verifyInvalid},
+		{"speed too slow", forge(ReplayEvent{Frame: 0, Speed: maxSpeed + 1}), verifyInvalid},
+		{"negative speed", forge(ReplayEvent{Frame: 0, Speed: -6}), verifyInvalid},
+		{"diagonal move", forge(ReplayEvent{Frame: 0, DX: 1, DY: 1}), verifyInvalid},
+		{"two-cell move", forge(ReplayEvent{Frame: 0, DX: 2}), verifyInvalid},
+		{"reversal", forge(ReplayEvent{Frame: 0, DX: -1}), verifyInvalid},
+	}
+	for _, tt := range tests {
+		edited := e
+		replay := *e.Replay
+		edited.Replay = &replay
+		tt.edit(&edited)
+
+		r := verifyEntry(testKey, edited, maxReplayFrames)
+		if r.Status != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, r.Status, tt.want)
+		}
+		if !r.Status.failed() {
+			t.Errorf("%s: edited entry was not counted as failed", tt.name)
+		}
+	}
+}
+
+// The Leaderboards screen checks a board a slice per tick, showing the
+// entries as pending until then, and skips runs over its own frame cap.
+func TestVerifyBoardAcrossTicks(t *testing.T) {
+	good := recordRun(t, testKey, 5)
+	edited := good
+	edited.Score++
+	long := good
+	long.Replay = &Replay{Events: good.Replay.Events, Frames: maxInGameReplayFrames + 1}
+
+	g := &Game{}
+	board := g.profiles.Leaderboards.board(testKey)
+	board.Entries = []LeaderboardEntry{good, edited, long}
+	name := testKey.String()
+
+	results, done := g.verifyBoard(name)
+	if done {
+		t.Fatal("board verified before any tick")
+	}
+	for i, r := range results {
+		if r.Status != verifyPending || r.Status.failed() {
+			t.Errorf("entry %d: got %v before any tick, want a pending entry", i+1, r.Status)
+		}
+	}
+
+	ticks := 0
+	for ; !done; ticks++ {
+		if ticks > 2*good.Replay.Frames/verifyStepsPerTick+10 {
+			t.Fatal("board check never finished")
+		}
+		g.updateVerification()
+		results, done = g.verifyBoard(name)
+	}
+	if ticks < 2 {
+		t.Errorf("board verified in %d tick, want the work spread out", ticks)
+	}
+
+	want := []verifyStatus{verifyOK, verifyMismatch, verifySkipped}
+	for i, r := range results {
+		if r.Status != want[i] {
+			t.Errorf("entry %d: got %v, want %v", i+1, r.Status, want[i])
+		}
+	}
+
+	// A new entry on the board checks it again
+	g.forgetVerification(name)
+	if _, done := g.verifyBoard(name); done {
+		t.Error("board still verified after its entries changed")
+	}
+}
+
+func TestVerifyCommand(t *testing.T) {
+	writeSave := func(entries ...LeaderboardEntry) string {
+		var boards Leaderboards
+		boards.board(testKey).Entries = entries
+		data, err := json.Marshal(ProfileStore{Leaderboards: boards})
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(t.TempDir(), "save.json")
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	good := recordRun(t, testKey, 3)
+	edited := good
+	edited.Score++
+	missing := good
+	missing.Replay = nil
+	forged := good
+	forged.Replay = &Replay{
+		Events: append([]ReplayEvent{{Frame: 0, Speed: 1}}, good.Replay.Events...),
+		Frames: good.Replay.Frames,
+	}
+
+	tests := []struct {
+		name    string
+		entries []LeaderboardEntry
+		want    int
+	}{
+		{"reproducible", []LeaderboardEntry{good}, 0},
+		{"edited score", []LeaderboardEntry{good, edited}, 1},
+		{"missing replay", []LeaderboardEntry{good, missing}, 1},
+		{"forged input", []LeaderboardEntry{good, forged}, 1},
+	}
+	for _, tt := range tests {
+		if got := runVerifyCommand([]string{writeSave(tt.entries...)}); got != tt.want {
+			t.Errorf("%s: exit code %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ==================== RULE SETS ====================
+
+// Rules are the gameplay variations a run is played under. Everything here
+// must be reproducible from a BoardKey so replays can be verified.
+type Rules struct {
+	Name           string  `json:"name"`
+	Walls          bool    `json:"walls"`            // solid edges instead of wrap-around
+	GrowthPerFood  int     `json:"growth_per_food"`  // segments added per food
+	PowerUpChance  float64 `json:"power_up_chance"`  // chance per spawn attempt
+	PowerUpWeights [3]int  `json:"power_up_weights"` // bonus, speed boost, shield
+	StartSpeed     int     `json:"start_speed"`      // frames per move at the start of a run
+	Difficulty     string  `json:"difficulty"`       // progression preset, see difficulties
+}
+
+var classicRules = Rules{
+	Name:           "classic",
+	Walls:          false,
+	GrowthPerFood:  2,
+	PowerUpChance:  0.15,
+	PowerUpWeights: [3]int{1, 1, 1},
+	StartSpeed:     10,
+}
+
+var powerUpIDs = []string{"bonus", "speed", "shield"}
+
+// Rule presets selectable from Options. Each has its own leaderboards.
+var rulePresets = []Rules{
+	classicRules,
+	{
+		Name:           "walls",
+		Walls:          true,
+		GrowthPerFood:  2,
+		PowerUpChance:  0.15,
+		PowerUpWeights: [3]int{1, 1, 1},
+		StartSpeed:     10,
+	},
+	{
+		Name:           "feast",
+		GrowthPerFood:  1,
+		PowerUpChance:  0.4,
+		PowerUpWeights: [3]int{2, 1, 1},
+		StartSpeed:     10,
+	},
+	{
+		Name:           "glutton",
+		GrowthPerFood:  4,
+		PowerUpChance:  0.1,
+		PowerUpWeights: [3]int{1, 1, 0},
+		StartSpeed:     10,
+	},
+}
+
+func rulePreset(name string) Rules {
+	for _, p := range rulePresets {
+		if p.Name == name {
+			return p
+		}
+	}
+	return classicRules
+}
+
+func rulePresetTitle(name string) string {
+	return tr("rules." + rulePreset(name).Name)
+}
+
+// rulesForKey rebuilds the rules a leaderboard entry was played under.
+func rulesForKey(key BoardKey) Rules {
+	switch key.Mode {
+	case challengeDaily, challengeWeekly:
+		return challengeFor(key.Mode, key.Rules).Rules
+	}
+	return parseRulesName(key.Rules)
+}
+
+// composeRules builds the rules for a normal run from a preset, a difficulty
+// curve and an optional starting speed (frames per move, 0 for the preset's).
+// The name encodes all three so the run can be rebuilt from its leaderboard
+// key: "walls+curve-classic@6". Classic on a curve keeps its older
+// "curve-<id>" name. The starting speed always ends up within
+// [minSpeed, maxSpeed], whatever a curve or a hand-edited key asks for.
+func composeRules(preset string, d Difficulty, startSpeed int) Rules {
+	rules := rulePreset(preset)
+	name := rules.Name
+	if d.progressive() {
+		if name == classicRules.Name {
+			name = d.rulesName()
+		} else {
+			name += "+" + d.rulesName()
+		}
+	}
+	if startSpeed > 0 {
+		startSpeed = clampSpeed(startSpeed)
+		rules.StartSpeed = startSpeed
+		name += "@" + strconv.Itoa(startSpeed)
+	}
+	rules.Name = name
+	rules.Difficulty = d.ID
+	rules.StartSpeed = clampSpeed(rules.StartSpeed + d.StartSpeedDelta)
+	return rules
+}
+
+// clampSpeed keeps frames per move within the range the game allows. Much
+// lower and a speed boost halves it to zero, which step divides by.
+func clampSpeed(frames int) int {
+	if frames < minSpeed {
+		return minSpeed
+	}
+	if frames > maxSpeed {
+		return maxSpeed
+	}
+	return frames
+}
+
+// parseRulesName is the inverse of composeRules.
+func parseRulesName(name string) Rules {
+	startSpeed := 0
+	if i := strings.LastIndex(name, "@"); i >= 0 {
+		startSpeed, _ = strconv.Atoi(name[i+1:])
+		name = name[:i]
+	}
+	preset, d := classicRules.Name, difficulties[0]
+	for _, part := range strings.Split(name, "+") {
+		if curve, ok := difficultyFromRulesName(part); ok {
+			d = curve
+		} else {
+			preset = part
+		}
+	}
+	return composeRules(preset, d, startSpeed)
+}
+
+// pickPowerUp chooses a power-up type using the weighted mix.
+func (r Rules) pickPowerUp(roll func(n int) int) int {
+	total := 0
+	for _, w := range r.PowerUpWeights {
+		total += w
+	}
+	if total <= 0 {
+		return roll(len(r.PowerUpWeights))
+	}
+	n := roll(total)
+	for i, w := range r.PowerUpWeights {
+		if n < w {
+			return i
+		}
+		n -= w
+	}
+	return 0
+}
+
+// Summary describes the rules for menus, one line per variation.
+func (r Rules) Summary() []string {
+	edges := tr("rules.wrap")
+	if r.Walls {
+		edges = tr("rules.solid")
+	}
+
+	mix := ""
+	for i, w := range r.PowerUpWeights {
+		if w == 0 {
+			continue
+		}
+		if mix != "" {
+			mix += ", "
+		}
+		mix += fmt.Sprintf("%s x%d", tr("powerup."+powerUpIDs[i]), w)
+	}
+	if mix == "" {
+		mix = tr("rules.no_powerups")
+	}
+
+	return []string{
+		edges,
+		tr("rules.growth", r.GrowthPerFood),
+		tr("rules.powerups", int(r.PowerUpChance*100+0.5), mix),
+		tr("rules.start_speed", maxSpeed-r.StartSpeed+minSpeed),
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRulesNameRoundTrip(t *testing.T) {
+	for _, preset := range rulePresets {
+		for _, d := range difficulties {
+			for _, speed := range []int{0, 6, 14} {
+				r := composeRules(preset.Name, d, speed)
+				if got := parseRulesName(r.Name); !reflect.DeepEqual(got, r) {
+					t.Errorf("%s/%s@%d: %q parses to %+v, want %+v", preset.Name, d.ID, speed, r.Name, got, r)
+				}
+			}
+		}
+	}
+}
+
+func TestRulesNames(t *testing.T) {
+	casual := difficultyByID("casual")
+	tests := []struct {
+		preset string
+		d      Difficulty
+		speed  int
+		want   string
+	}{
+		{"classic", difficulties[0], 0, "classic"},
+		{"classic", casual, 0, "curve-casual"}, // older boards keep their name
+		{"walls", casual, 0, "walls+curve-casual"},
+		{"feast", difficulties[0], 6, "feast@6"},
+		{"glutton", casual, 8, "glutton+curve-casual@8"},
+	}
+	for _, tt := range tests {
+		if got := composeRules(tt.preset, tt.d, tt.speed).Name; got != tt.want {
+			t.Errorf("composeRules(%s, %s, %d) = %q, want %q", tt.preset, tt.d.ID, tt.speed, got, tt.want)
+		}
+	}
+}
+
+func TestUnknownRulesFallBackToClassic(t *testing.T) {
+	if got := parseRulesName("no-such-preset").Walls; got != classicRules.Walls {
+		t.Errorf("unknown preset has walls %v, want classic's %v", got, classicRules.Walls)
+	}
+}
+
+func TestStartSpeedIsClamped(t *testing.T) {
+	insane := difficultyByID("insane")
+	tests := []struct {
+		name string
+		r    Rules
+		want int
+	}{
+		{"insane at the fastest speed", composeRules("classic", insane, minSpeed), minSpeed},
+		{"tampered fast key", parseRulesName("walls@2"), minSpeed},
+		{"tampered slow key", parseRulesName("feast@99"), maxSpeed},
+		{"negative key", parseRulesName("classic@-3"), classicRules.StartSpeed},
+	}
+	for _, tt := range tests {
+		if tt.r.StartSpeed != tt.want {
+			t.Errorf("%s: start speed %d, want %d", tt.name, tt.r.StartSpeed, tt.want)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+// Frames a scene change takes to fade in from black
+const sceneFadeFrames = 20
+
+// ==================== SCENES ====================
+
+// Scene is one screen of the game. Scenes live on a stack: only the top one
+// receives input, and overlays such as the pause screen draw on top of the
+// scenes beneath them.
+type Scene interface {
+	Update(g *Game) error
+	Draw(g *Game, screen *ebiten.Image)
+	Enter(g *Game) // after the scene is pushed or set
+	Exit(g *Game)  // before the scene is removed
+}
+
+// overlayScene is implemented by scenes that draw over the scene below
+// instead of replacing it.
+type overlayScene interface {
+	Overlay() bool
+}
+
+// backHandler is implemented by scenes that do something other than close
+// when Back is pressed.
+type backHandler interface {
+	Back(g *Game)
+}
+
+func (g *Game) scene() Scene {
+	if len(g.scenes) == 0 {
+		return nil
+	}
+	return g.scenes[len(g.scenes)-1]
+}
+
+// below is the scene under the top one, or nil.
+func (g *Game) below() Scene {
+	if len(g.scenes) < 2 {
+		return nil
+	}
+	return g.scenes[len(g.scenes)-2]
+}
+
+func (g *Game) pushScene(s Scene) {
+	g.scenes = append(g.scenes, s)
+	s.Enter(g)
+	g.syncMusic()
+}
+
+func (g *Game) popScene() {
+	if len(g.scenes) == 0 {
+		return
+	}
+	g.scene().Exit(g)
+	g.scenes = g.scenes[:len(g.scenes)-1]
+	g.syncMusic()
+}
+
+// replaceScene swaps the top scene, e.g. pause for the menu.
+func (g *Game) replaceScene(s Scene) {
+	if len(g.scenes) > 0 {
+		g.scene().Exit(g)
+		g.scenes = g.scenes[:len(g.scenes)-1]
+	}
+	g.pushScene(s)
+}
+
+// setScene clears the stack and starts over from s with a fade from black.
+func (g *Game) setScene(s Scene) {
+	for len(g.scenes) > 0 {
+		g.popScene()
+	}
+	g.pushScene(s)
+	if !g.access().ReducedMotion {
+		g.sceneFade = sceneFadeFrames
+	}
+}
+
+// back closes the top scene unless it handles Back itself. The bottom scene
+// is never closed.
+func (g *Game) back() {
+	if h, ok := g.scene().(backHandler); ok {
+		h.Back(g)
+		return
+	}
+	if len(g.scenes) > 1 {
+		g.popScene()
+	}
+}
+
+// runActive reports whether a run is in progress anywhere on the stack, for
+// example under the pause screen and the menu opened from it.
+func (g *Game) runActive() bool {
+	for _, s := range g.scenes {
+		if _, ok := s.(*playScene); ok && !g.over {
+			return true
+		}
+	}
+	return false
+}
+
+// syncMusic plays the background loop only while a run is on screen.
+func (g *Game) syncMusic() {
+	if g.bgPlayer == nil {
+		return
+	}
+	switch g.scene().(type) {
+	case *playScene, *gameOverScene:
+		g.bgPlayer.Play()
+	default:
+		g.bgPlayer.Pause()
+	}
+}
+
+func (g *Game) playing() bool {
+	_, ok := g.scene().(*playScene)
+	return ok
+}
+
+// runUnderneath reports whether the top scene sits over a run in progress.
+func (g *Game) runUnderneath() bool {
+	_, ok := g.below().(*playScene)
+	return ok
+}
+
+func (g *Game) updateScenes() error {
+	if g.sceneFade > 0 {
+		g.sceneFade--
+	}
+	return g.scene().Update(g)
+}
+
+// drawScenes draws the top scene and every overlay-covered scene beneath it.
+func (g *Game) drawScenes(screen *ebiten.Image) {
+	first := len(g.scenes) - 1
+	for first > 0 {
+		o, ok := g.scenes[first].(overlayScene)
+		if !ok || !o.Overlay() {
+			break
+		}
+		first--
+	}
+	for _, s := range g.scenes[first:] {
+		s.Draw(g, screen)
+	}
+
+	if g.sceneFade > 0 {
+		alpha := uint8(255 * g.sceneFade / sceneFadeFrames)
+		ebitenutil.DrawRect(screen, 0, 0, float64(g.screenWidth), float64(g.screenHeight), color.RGBA{0, 0, 0, alpha})
+	}
+}
+
+// ==================== GAME SCENES ====================
+
+type titleScene struct{}
+
+func (titleScene) Update(g *Game) error               { return g.updateTitleScreen() }
+func (titleScene) Draw(g *Game, screen *ebiten.Image) { g.drawTitleScreen(screen) }
+func (titleScene) Enter(g *Game)                      {}
+func (titleScene) Exit(g *Game)                       {}
+
+type playScene struct{}
+
+func (playScene) Update(g *Game) error { return g.updateGameplay() }
+func (playScene) Draw(g *Game, screen *ebiten.Image) {
+	g.renderer.drawAnimatedGrid(screen)
+	g.drawGameplay(screen)
+}
+func (playScene) Enter(g *Game) {}
+func (playScene) Exit(g *Game)  {}
+
+// Back during a run opens the menu over it.
+func (playScene) Back(g *Game) { g.pushScene(&menuScene{}) }
+
+type pauseScene struct{}
+
+func (pauseScene) Update(g *Game) error               { return g.updatePaused() }
+func (pauseScene) Draw(g *Game, screen *ebiten.Image) { g.drawPauseOverlay(screen) }
+func (pauseScene) Enter(g *Game)                      {}
+func (pauseScene) Exit(g *Game)                       {}
+func (pauseScene) Overlay() bool                      { return true }
+func (pauseScene) Back(g *Game)                       { g.replaceScene(&menuScene{}) }
+
+type gameOverScene struct{}
+
+func (gameOverScene) Update(g *Game) error               { return g.updateGameOver() }
+func (gameOverScene) Draw(g *Game, screen *ebiten.Image) { g.drawGameOverOverlay(screen) }
+func (gameOverScene) Enter(g *Game)                      {}
+func (gameOverScene) Exit(g *Game)                       { g.recordRunStats() }
+func (gameOverScene) Overlay() bool                      { return true }
+
+// Back opens the menu, unless a name is being typed for the leaderboard.
+func (gameOverScene) Back(g *Game) {
+	if !g.enteringName {
+		g.pushScene(&menuScene{})
+	}
+}
+
+// menuScene draws over a paused run, and over nothing otherwise, so text on
+// the title and game-over screens never shows through.
+type menuScene struct {
+	overRun bool
+}
+
+func (m *menuScene) Update(g *Game) error               { return g.updateMenu() }
+func (m *menuScene) Draw(g *Game, screen *ebiten.Image) { g.drawMenuScreen(screen) }
+func (m *menuScene) Exit(g *Game)                       {}
+func (m *menuScene) Overlay() bool                      { return m.overRun }
+
+func (m *menuScene) Enter(g *Game) {
+	m.overRun = g.runUnderneath()
+}
+
+// screenScene adapts a menu screen's update and draw methods into a Scene.
+// back, when set, is offered Back first and returns true if it used it.
+type screenScene struct {
+	update func() error
+	draw   func(screen *ebiten.Image)
+	back   func() bool
+	exit   func()
+}
+
+func (s *screenScene) Update(g *Game) error               { return s.update() }
+func (s *screenScene) Draw(g *Game, screen *ebiten.Image) { s.draw(screen) }
+func (s *screenScene) Enter(g *Game)                      {}
+
+func (s *screenScene) Exit(g *Game) {
+	if s.exit != nil {
+		s.exit()
+	}
+}
+
+func (s *screenScene) Back(g *Game) {
+	if s.back != nil && s.back() {
+		return
+	}
+	g.popScene()
+}
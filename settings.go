@@ -0,0 +1,371 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/audio"
+)
+
+const settingsFile = "snake_settings.json"
+
+const (
+	windowFullscreen = "fullscreen"
+	windowWindowed   = "windowed"
+	windowBorderless = "borderless"
+
+	effectsLow    = "low"
+	effectsMedium = "medium"
+	effectsHigh   = "high"
+)
+
+// ==================== SETTINGS ====================
+
+// Settings are the machine-wide options, shared by every profile and applied
+// at startup: the window, audio, controllers and language all belong to the
+// machine rather than to whoever is playing. Per-person choices live on the
+// Profile instead, see PlayerSettings.
+type Settings struct {
+	WindowMode string `json:"window_mode"`
+	Width      int    `json:"width"` // windowed resolution
+	Height     int    `json:"height"`
+	VSync      bool   `json:"vsync"`
+
+	MasterVolume int `json:"master_volume"` // percent
+	MusicVolume  int `json:"music_volume"`
+	SFXVolume    int `json:"sfx_volume"`
+
+	Deadzone   int            `json:"deadzone"`    // stick deadzone, percent
+	PadPlayers map[string]int `json:"pad_players"` // controller SDL id to player
+
+	Language string `json:"language"` // locale ID, see loadLocales
+}
+
+var defaultSettings = Settings{
+	WindowMode:   windowFullscreen,
+	Width:        1280,
+	Height:       720,
+	VSync:        true,
+	MasterVolume: 100,
+	MusicVolume:  100,
+	SFXVolume:    100,
+	Deadzone:     defaultDeadzone,
+	Language:     englishLocale.ID,
+}
+
+// PlayerSettings are the options each profile keeps for itself: how the
+// game looks to them and what kind of run they start.
+type PlayerSettings struct {
+	Effects    string `json:"effects"`     // particle, meteor and star density
+	PostFX     PostFX `json:"post_fx"`     // effects on the finished frame, see postfx.go
+	StartSpeed int    `json:"start_speed"` // frames per move, 0 for the rules' default
+	Arena      string `json:"arena"`       // arena size preset
+	Rules      string `json:"rules"`       // rule preset for normal runs
+}
+
+var defaultPlayerSettings = PlayerSettings{
+	Effects: effectsHigh,
+	PostFX:  defaultPostFX,
+	Arena:   "auto",
+	Rules:   classicRules.Name,
+}
+
+var windowModes = []string{windowFullscreen, windowWindowed, windowBorderless}
+
+var resolutions = []Point{{800, 600}, {1024, 768}, {1280, 720}, {1600, 900}, {1920, 1080}}
+
+var effectsLevels = []string{effectsLow, effectsMedium, effectsHigh}
+
+// ArenaPreset fixes the arena size for normal runs. "auto" sizes it from the
+// window as before.
+type ArenaPreset struct {
+	ID   string
+	Size Point
+}
+
+var arenaPresets = []ArenaPreset{
+	{"auto", Point{}},
+	{"small", Point{24, 16}},
+	{"medium", Point{32, 24}},
+	{"large", Point{48, 30}},
+	{"huge", Point{100, 60}},
+	{"vast", Point{200, 200}},
+}
+
+func (a ArenaPreset) Title() string {
+	return tr("arena." + a.ID)
+}
+
+func arenaPresetByID(id string) ArenaPreset {
+	for _, a := range arenaPresets {
+		if a.ID == id {
+			return a
+		}
+	}
+	return arenaPresets[0]
+}
+
+func loadSettings() Settings {
+	s := defaultSettings
+	if data, err := os.ReadFile(settingsFile); err == nil {
+		json.Unmarshal(data, &s)
+	}
+	return s
+}
+
+// legacyPlayerSettings reads the player options older versions kept in the
+// settings file for the whole machine, so existing profiles keep them.
+func legacyPlayerSettings() PlayerSettings {
+	p := defaultPlayerSettings
+	if data, err := os.ReadFile(settingsFile); err == nil {
+		json.Unmarshal(data, &p)
+	}
+	return p
+}
+
+// validate resets a hand-edited starting speed outside the Options range to
+// the default.
+func (p *PlayerSettings) validate() {
+	if p.StartSpeed != 0 && clampSpeed(p.StartSpeed) != p.StartSpeed {
+		p.StartSpeed = 0
+	}
+}
+
+// playerSettings is the active profile's options. Headless games, such as
+// replays being verified, have no profile and get the defaults.
+func (g *Game) playerSettings() *PlayerSettings {
+	if g.profile == nil || g.profile.Settings == nil {
+		p := defaultPlayerSettings
+		return &p
+	}
+	return g.profile.Settings
+}
+
+func (g *Game) saveSettings() {
+	data, _ := json.MarshalIndent(g.settings, "", "  ")
+	os.WriteFile(settingsFile, data, 0644)
+}
+
+// saveOptions saves both halves of the Options screen: the machine's
+// settings and the active profile's own.
+func (g *Game) saveOptions() {
+	g.saveSettings()
+	g.saveGameData()
+}
+
+// applyDisplaySettings sets the window mode, size and vsync. It is safe to
+// call before the game loop starts.
+func (g *Game) applyDisplaySettings() {
+	s := g.settings
+	ebiten.SetVsyncEnabled(s.VSync)
+	g.isFullscreen = s.WindowMode == windowFullscreen
+	ebiten.SetFullscreen(g.isFullscreen)
+	if !g.isFullscreen {
+		ebiten.SetWindowDecorated(s.WindowMode != windowBorderless)
+		ebiten.SetWindowSize(s.Width, s.Height)
+	}
+}
+
+// applyAudioSettings sets the music and effect volumes.
+func (g *Game) applyAudioSettings() {
+	master := float64(g.settings.MasterVolume) / 100
+	if g.bgPlayer != nil {
+		g.bgPlayer.SetVolume(master * float64(g.settings.MusicVolume) / 100)
+	}
+	for _, p := range []*audio.Player{g.eatPlayer, g.comboPlayer, g.powerUpPlayer, g.gameOverPlayer} {
+		if p != nil {
+			p.SetVolume(master * float64(g.settings.SFXVolume) / 100)
+		}
+	}
+}
+
+// effectsScale is the share of particles, meteors and stars drawn.
+func (g *Game) effectsScale() float64 {
+	switch g.playerSettings().Effects {
+	case effectsLow:
+		return 0.25
+	case effectsMedium:
+		return 0.6
+	}
+	return 1
+}
+
+// toggleFullscreen flips between fullscreen and a window and remembers it.
+func (g *Game) toggleFullscreen() {
+	if g.settings.WindowMode == windowFullscreen {
+		g.settings.WindowMode = windowWindowed
+	} else {
+		g.settings.WindowMode = windowFullscreen
+	}
+	g.applyDisplaySettings()
+	g.saveSettings()
+}
+
+// cycleString steps through a list of ids, starting from the first if the
+// current one is unknown.
+func cycleString(list []string, current string, delta int) string {
+	index := 0
+	for i, s := range list {
+		if s == current {
+			index = i
+		}
+	}
+	return list[(index+delta+len(list))%len(list)]
+}
+
+func stepVolume(v *int, delta int) {
+	*v += delta * 10
+	if *v < 0 {
+		*v = 0
+	}
+	if *v > 100 {
+		*v = 100
+	}
+}
+
+func onOff(b bool) string {
+	if b {
+		return tr("common.on")
+	}
+	return tr("common.off")
+}
+
+// ==================== OPTION SCREENS ====================
+
+// optionScreen is the layout shared by the Options, Accessibility and
+// Controllers screens: a title, a list of settings and hint lines.
+func (g *Game) optionScreen(title string, list *List, hints ...string) Widget {
+	children := []Widget{label(title, palette.UI.Title), Gap(30), list, Gap(20)}
+	for _, hint := range hints {
+		children = append(children, label(hint, palette.UI.Text))
+	}
+	return &Screen{
+		Backdrop: palette.UI.Overlay,
+		Children: []Widget{&Panel{Anchor: AnchorCenter, Spacing: 7, Children: children}},
+	}
+}
+
+// volumeSlider edits one of the volume percentages.
+func (g *Game) volumeSlider(name string, v *int) Widget {
+	return &Slider{
+		Label: name,
+		Value: float64(*v) / 100,
+		Text:  fmt.Sprintf("%d%%", *v),
+		OnChange: func(d int) {
+			stepVolume(v, d)
+			g.applyAudioSettings()
+		},
+	}
+}
+
+// ==================== OPTIONS SCREEN ====================
+
+func (g *Game) optionRows() []Widget {
+	s, p := &g.settings, g.playerSettings()
+
+	speed := tr("options.speed_default")
+	if p.StartSpeed != 0 {
+		speed = fmt.Sprintf("%d", maxSpeed-p.StartSpeed+minSpeed)
+	}
+	arena := arenaPresetByID(p.Arena)
+	arenaName := arena.Title()
+	if arena.Size.X != 0 {
+		arenaName = fmt.Sprintf("%s %dx%d", arena.Title(), arena.Size.X, arena.Size.Y)
+	}
+
+	return []Widget{
+		&Choice{Label: tr("options.language"), Value: lang.Name, OnChange: g.cycleLanguage},
+		&Choice{Label: tr("options.window_mode"), Value: tr("window." + s.WindowMode), OnChange: func(d int) {
+			s.WindowMode = cycleString(windowModes, s.WindowMode, d)
+			g.applyDisplaySettings()
+		}},
+		&Choice{Label: tr("options.resolution"), Value: fmt.Sprintf("%dx%d", s.Width, s.Height), OnChange: func(d int) {
+			index := 0
+			for i, r := range resolutions {
+				if r.X == s.Width && r.Y == s.Height {
+					index = i
+				}
+			}
+			r := resolutions[(index+d+len(resolutions))%len(resolutions)]
+			s.Width, s.Height = r.X, r.Y
+			g.applyDisplaySettings()
+		}},
+		&Toggle{Label: tr("options.vsync"), On: s.VSync, OnChange: func() {
+			s.VSync = !s.VSync
+			g.applyDisplaySettings()
+		}},
+		g.volumeSlider(tr("options.master_volume"), &s.MasterVolume),
+		g.volumeSlider(tr("options.music_volume"), &s.MusicVolume),
+		g.volumeSlider(tr("options.sfx_volume"), &s.SFXVolume),
+		&Choice{Label: tr("options.effects"), Value: tr("effects." + p.Effects), OnChange: func(d int) {
+			p.Effects = cycleString(effectsLevels, p.Effects, d)
+		}},
+		&Choice{Label: tr("options.start_speed"), Value: speed, OnChange: func(d int) {
+			// Faster means fewer frames per move; Default sits below the slowest
+			frames := p.StartSpeed
+			if frames == 0 {
+				frames = maxSpeed + 1
+			}
+			frames -= d
+			switch {
+			case frames > maxSpeed+1:
+				frames = minSpeed
+			case frames < minSpeed:
+				frames = maxSpeed + 1
+			}
+			p.StartSpeed = frames
+			if frames > maxSpeed {
+				p.StartSpeed = 0
+			}
+		}},
+		&Choice{Label: tr("options.arena"), Value: arenaName, OnChange: func(d int) {
+			ids := make([]string, len(arenaPresets))
+			for i, a := range arenaPresets {
+				ids[i] = a.ID
+			}
+			p.Arena = cycleString(ids, p.Arena, d)
+		}},
+		&Button{Label: tr("options.postfx"), OnClick: g.openPostFX},
+		&Button{Label: tr("options.controls"), OnClick: g.openControls},
+		&Button{Label: trn("options.controllers", len(g.pads)), OnClick: g.openControllers},
+		&Choice{Label: tr("options.rules"), Value: rulePresetTitle(p.Rules), OnChange: func(d int) {
+			ids := make([]string, len(rulePresets))
+			for i, preset := range rulePresets {
+				ids[i] = preset.Name
+			}
+			p.Rules = cycleString(ids, p.Rules, d)
+		}},
+	}
+}
+
+func (g *Game) openOptions() {
+	g.optionsCursor = 0
+	g.pushScene(&screenScene{update: g.updateOptions, draw: g.drawOptionsScreen})
+}
+
+func (g *Game) optionsView() Widget {
+	hint := ""
+	p := g.playerSettings()
+	for _, line := range composeRules(p.Rules, difficultyByID(g.profile.Difficulty), p.StartSpeed).Summary() {
+		if hint != "" {
+			hint += " | "
+		}
+		hint += line
+	}
+	list := &List{Items: g.optionRows(), Cursor: &g.optionsCursor, RowHeight: 30, OnChange: g.saveOptions}
+	return g.optionScreen(tr("options.title"), list,
+		hint,
+		tr("options.next_run"),
+		tr("options.hint"))
+}
+
+func (g *Game) updateOptions() error {
+	g.updateUI(g.optionsView())
+	return nil
+}
+
+func (g *Game) drawOptionsScreen(screen *ebiten.Image) {
+	g.renderUI(screen, g.optionsView())
+}
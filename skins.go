@@ -0,0 +1,264 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"io/fs"
+	"log"
+	"path"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	skinsDir         = "assets/skins"
+	skinManifestFile = "skin.json"
+	defaultSkinID    = "classic"
+)
+
+// ==================== SKINS ====================
+
+// Skin is a sprite sheet for the snake, food and power-ups. The built-in
+// "classic" skin has no sheet and keeps the procedural look.
+//
+// Sheets are a grid of square tiles laid out as:
+//
+//	row 0: head facing up, right, down, left
+//	row 1: body horizontal, vertical, corners up-right, right-down, down-left, left-up
+//	row 2: tail travelling up, right, down, left
+//	row 3: food, bonus, speed, shield, asteroid hazard
+type Skin struct {
+	ID       string
+	Name     string
+	tileSize int
+	sheet    *ebiten.Image
+}
+
+type skinManifest struct {
+	Name     string `json:"name"`
+	TileSize int    `json:"tile_size"`
+	Sheet    string `json:"sheet"`
+}
+
+const (
+	skinSheetCols = 6
+	skinSheetRows = 4
+)
+
+// Tile rows and columns within a sheet
+const (
+	tileRowHead = 0
+	tileRowBody = 1
+	tileRowTail = 2
+	tileRowItem = 3
+
+	tileBodyH   = 0
+	tileBodyV   = 1
+	tileCornerU = 2 // up-right, followed by right-down, down-left, left-up
+
+	tileFood    = 0
+	tilePowerUp = 1 // bonus, followed by speed and shield
+	tileHazard  = 4
+)
+
+// loadSkins returns the built-in skin followed by every valid skin found in
+// dir, bundled ones first and then the player's own. Broken skins are logged
+// and skipped rather than stopping the game.
+func loadSkins(dir string) []*Skin {
+	skins := []*Skin{{ID: defaultSkinID, Name: "Classic"}}
+	seen := map[string]bool{defaultSkinID: true}
+
+	for _, fsys := range assetSources() {
+		entries, err := fs.ReadDir(fsys, dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if !e.IsDir() || seen[e.Name()] {
+				continue
+			}
+			skin, err := loadSkin(fsys, path.Join(dir, e.Name()))
+			if err != nil {
+				log.Printf("skin %s: %v", e.Name(), err)
+				continue
+			}
+			skin.ID = e.Name()
+			seen[skin.ID] = true
+			skins = append(skins, skin)
+		}
+	}
+	return skins
+}
+
+func loadSkin(fsys fs.FS, dir string) (*Skin, error) {
+	data, err := fs.ReadFile(fsys, path.Join(dir, skinManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	var m skinManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", skinManifestFile, err)
+	}
+	if m.TileSize <= 0 || m.Sheet == "" {
+		return nil, fmt.Errorf("%s needs a tile_size and a sheet", skinManifestFile)
+	}
+
+	f, err := fsys.Open(path.Join(dir, m.Sheet))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", m.Sheet, err)
+	}
+	if b := img.Bounds(); b.Dx() < skinSheetCols*m.TileSize || b.Dy() < skinSheetRows*m.TileSize {
+		return nil, fmt.Errorf("%s is %dx%d, need at least %dx%d", m.Sheet, b.Dx(), b.Dy(),
+			skinSheetCols*m.TileSize, skinSheetRows*m.TileSize)
+	}
+
+	name := m.Name
+	if name == "" {
+		name = path.Base(dir)
+	}
+	return &Skin{
+		Name:     name,
+		tileSize: m.TileSize,
+		sheet:    ebiten.NewImageFromImage(img),
+	}, nil
+}
+
+func (s *Skin) sprite() bool {
+	return s != nil && s.sheet != nil
+}
+
+func (s *Skin) tile(col, row int) *ebiten.Image {
+	r := image.Rect(col*s.tileSize, row*s.tileSize, (col+1)*s.tileSize, (row+1)*s.tileSize)
+	return s.sheet.SubImage(r).(*ebiten.Image)
+}
+
+func (g *Game) currentSkin() *Skin {
+	for _, s := range g.skins {
+		if s.ID == g.profile.Skin {
+			return s
+		}
+	}
+	return g.skins[0]
+}
+
+// cycleSkin steps through the installed skins for the active profile.
+func (g *Game) cycleSkin(delta int) {
+	current := 0
+	for i, s := range g.skins {
+		if s.ID == g.profile.Skin {
+			current = i
+		}
+	}
+	g.profile.Skin = g.skins[(current+delta+len(g.skins))%len(g.skins)].ID
+	g.saveGameData()
+}
+
+// ==================== SPRITE RENDERING ====================
+
+// stepDir is the unit step from a to its neighbour b, across wrapped edges.
+func (g *Game) stepDir(a, b Point) Point {
+	return Point{
+		int(wrapDelta(float64(b.X-a.X), g.gridW)),
+		int(wrapDelta(float64(b.Y-a.Y), g.gridH)),
+	}
+}
+
+// dirIndex maps a direction to the up, right, down, left tile order.
+func dirIndex(d Point) int {
+	switch d {
+	case Point{0, -1}:
+		return 0
+	case Point{0, 1}:
+		return 2
+	case Point{-1, 0}:
+		return 3
+	}
+	return 1
+}
+
+// segmentTile picks the head, body, corner or tail piece for segment i.
+func (g *Game) segmentTile(skin *Skin, i int) *ebiten.Image {
+	n := len(g.snake)
+	switch {
+	case i == 0:
+		return skin.tile(dirIndex(g.dir), tileRowHead)
+	case i == n-1:
+		return skin.tile(dirIndex(g.stepDir(g.snake[i], g.snake[i-1])), tileRowTail)
+	}
+
+	a := g.stepDir(g.snake[i], g.snake[i-1])
+	b := g.stepDir(g.snake[i], g.snake[i+1])
+	if a.X == -b.X && a.Y == -b.Y {
+		if a.X != 0 {
+			return skin.tile(tileBodyH, tileRowBody)
+		}
+		return skin.tile(tileBodyV, tileRowBody)
+	}
+
+	// Corners, named after the two edges they join
+	has := func(d Point) bool { return a == d || b == d }
+	up, right, down := Point{0, -1}, Point{1, 0}, Point{0, 1}
+	switch {
+	case has(up) && has(right):
+		return skin.tile(tileCornerU, tileRowBody)
+	case has(right) && has(down):
+		return skin.tile(tileCornerU+1, tileRowBody)
+	case has(down):
+		return skin.tile(tileCornerU+2, tileRowBody)
+	}
+	return skin.tile(tileCornerU+3, tileRowBody)
+}
+
+func (g *Game) drawSpriteSnake(screen *ebiten.Image, skin *Skin) {
+	if len(g.snake) == 0 {
+		return
+	}
+	cell := float64(g.cellSize)
+	offsetX, offsetY, arena := g.snakeOrigin()
+	dst := screen.SubImage(arena).(*ebiten.Image)
+	scale := cell / float64(skin.tileSize)
+	t := g.moveProgress()
+
+	// Tail first so the head ends up on top
+	for i := len(g.snake) - 1; i >= 0; i-- {
+		tile := g.segmentTile(skin, i)
+		alpha := float32(1)
+		if i < len(g.trailOpacity) {
+			alpha = float32(0.35 + 0.65*g.trailOpacity[i])
+		}
+		if i == 0 && g.invulnerable > 0 {
+			if g.access().NoFlash {
+				alpha *= 0.7
+			} else if (g.frame/5)%2 == 0 {
+				alpha *= 0.5
+			}
+		}
+
+		g.forEachWrapCopy(g.segmentPosition(i, t), func(p Vector2) {
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Scale(scale, scale)
+			op.GeoM.Translate(offsetX+p.X*cell, offsetY+p.Y*cell)
+			op.ColorScale.ScaleAlpha(alpha)
+			dst.DrawImage(tile, op)
+		})
+	}
+}
+
+// drawSpriteItem draws an item icon centred on its cell at the given scale.
+func (g *Game) drawSpriteItem(screen *ebiten.Image, skin *Skin, p Point, col int, scale float64) {
+	cell := float64(g.cellSize)
+	offsetX, offsetY, _ := g.snakeOrigin()
+	size := cell * scale
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(size/float64(skin.tileSize), size/float64(skin.tileSize))
+	op.GeoM.Translate(offsetX+float64(p.X)*cell+(cell-size)/2, offsetY+float64(p.Y)*cell+(cell-size)/2)
+	screen.DrawImage(skin.tile(col, tileRowItem), op)
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ==================== SMOOTH SNAKE MOVEMENT ====================
+
+// recordMove remembers where every segment was before the snake moves, so
+// rendering can slide each one towards its new cell during the next tick.
+func (g *Game) recordMove() {
+	g.prevSnake = append(g.prevSnake[:0], g.snake...)
+	g.lastMoveFrame = g.frame
+}
+
+// moveProgress is how far through the current movement tick we are, 0..1.
+func (g *Game) moveProgress() float64 {
+	if g.speed <= 0 || len(g.prevSnake) == 0 {
+		return 1
+	}
+	t := float64(g.frame-g.lastMoveFrame) / float64(g.speed)
+	if t < 0 {
+		return 0
+	}
+	if t > 1 {
+		return 1
+	}
+	return t
+}
+
+// wrapDelta turns a step across the wrap-around edge into a single-cell step
+// in the direction the snake actually travelled.
+func wrapDelta(d float64, size int) float64 {
+	half := float64(size) / 2
+	if d > half {
+		return d - float64(size)
+	}
+	if d < -half {
+		return d + float64(size)
+	}
+	return d
+}
+
+// segmentPosition is the interpolated grid position of segment i. A growing
+// tail has no previous cell of its own and stays on the old tail cell.
+func (g *Game) segmentPosition(i int, t float64) Vector2 {
+	cur := g.snake[i]
+	prev := cur
+	if n := len(g.prevSnake); n > 0 {
+		if i < n {
+			prev = g.prevSnake[i]
+		} else {
+			prev = g.prevSnake[n-1]
+		}
+	}
+	dx := wrapDelta(float64(cur.X-prev.X), g.gridW)
+	dy := wrapDelta(float64(cur.Y-prev.Y), g.gridH)
+	return Vector2{float64(prev.X) + dx*t, float64(prev.Y) + dy*t}
+}
+
+// forEachWrapCopy calls fn with p and with p shifted by a full arena width
+// or height for every edge a segment centred on p overlaps.
+func (g *Game) forEachWrapCopy(p Vector2, fn func(p Vector2)) {
+	xs := []float64{p.X}
+	ys := []float64{p.Y}
+	if p.X < 0.5 {
+		xs = append(xs, p.X+float64(g.gridW))
+	} else if p.X > float64(g.gridW)-1.5 {
+		xs = append(xs, p.X-float64(g.gridW))
+	}
+	if p.Y < 0.5 {
+		ys = append(ys, p.Y+float64(g.gridH))
+	} else if p.Y > float64(g.gridH)-1.5 {
+		ys = append(ys, p.Y-float64(g.gridH))
+	}
+	for _, x := range xs {
+		for _, y := range ys {
+			fn(Vector2{x, y})
+		}
+	}
+}
+
+// snakeOrigin is the on-screen top-left of the arena and the arena's bounds.
+func (g *Game) snakeOrigin() (float64, float64, image.Rectangle) {
+	offsetX, offsetY := g.arenaOrigin()
+	arena := image.Rect(int(offsetX), int(offsetY), int(offsetX)+g.gridW*g.cellSize, int(offsetY)+g.gridH*g.cellSize)
+	return offsetX, offsetY, arena
+}
+
+func (g *Game) headDrawColor() color.RGBA {
+	// Special effects based on power-ups
+	if g.invulnerable > 0 {
+		// Flashing invulnerability
+		if g.flashOn(g.frame / 5) {
+			return palette.HeadFlash.rgba()
+		}
+	} else if g.speedBoostTime > 0 {
+		return palette.HeadBoost.rgba()
+	} else if g.slowMotionTime > 0 {
+		return palette.HeadSlow.rgba()
+	}
+	return palette.Head.rgba()
+}
+
+// segmentStyle returns the colour and radius (in cells) of segment i. Trail
+// fading blends towards the background instead of using alpha so the
+// overlapping joints do not show through each other.
+func (g *Game) segmentStyle(i int) (color.RGBA, float64) {
+	var c color.RGBA
+	var scale float64
+	if i == 0 {
+		// Enhanced head with pulsing effect
+		c = g.headDrawColor()
+		scale = 1.0 + 0.1*math.Sin(g.headPulse)
+	} else {
+		// Body with gradient effect
+		scale = 0.9 - float64(i)*0.01
+		if scale < 0.5 {
+			scale = 0.5
+		}
+		factor := float64(i) / float64(len(g.snake))
+		bodyColor := palette.Body
+		c = color.RGBA{
+			uint8(float64(bodyColor.R) * (1 - factor*0.4)),
+			uint8(float64(bodyColor.G) * (1 - factor*0.3)),
+			uint8(float64(bodyColor.B) * (1 - factor*0.4)),
+			bodyColor.A,
+		}
+	}
+
+	opacity := 1.0
+	bgColor := palette.Background
+	if i < len(g.trailOpacity) {
+		opacity = 0.35 + 0.65*g.trailOpacity[i]
+	}
+	c = color.RGBA{
+		uint8(float64(c.R)*opacity + float64(bgColor.R)*(1-opacity)),
+		uint8(float64(c.G)*opacity + float64(bgColor.G)*(1-opacity)),
+		uint8(float64(c.B)*opacity + float64(bgColor.B)*(1-opacity)),
+		255,
+	}
+	return c, scale / 2
+}
+
+// drawSmoothSnake draws the snake as a continuous tube: a circle on every
+// interpolated segment centre joined by thick lines, which gives rounded
+// corners. Everything is clipped to the arena and segments crossing a wrapped
+// edge are drawn on both sides. The shadow and the tube each go through the
+// batch in one draw call, however long the snake is.
+func (g *Game) drawSmoothSnake(screen *ebiten.Image) {
+	if len(g.snake) == 0 {
+		return
+	}
+
+	batch := &g.renderer.batch
+	cell := float64(g.cellSize)
+	offsetX, offsetY, arena := g.snakeOrigin()
+	if g.snakeLayer == nil || g.snakeLayer.Bounds().Dx() != g.screenWidth || g.snakeLayer.Bounds().Dy() != g.screenHeight {
+		g.snakeLayer = ebiten.NewImage(g.screenWidth, g.screenHeight)
+	}
+	g.snakeLayer.Clear()
+	layer := g.snakeLayer.SubImage(arena).(*ebiten.Image)
+
+	t := g.moveProgress()
+	positions := make([]Vector2, len(g.snake))
+	for i := range g.snake {
+		positions[i] = g.segmentPosition(i, t)
+	}
+
+	toScreen := func(p Vector2) (float64, float64) {
+		return offsetX + (p.X+0.5)*cell, offsetY + (p.Y+0.5)*cell
+	}
+
+	// drawCopies draws fn at p and again on the far side of any edge p overlaps
+	drawCopies := func(p Vector2, fn func(x, y float64)) {
+		g.forEachWrapCopy(p, func(c Vector2) {
+			fn(toScreen(c))
+		})
+	}
+
+	drawTube := func(dst *ebiten.Image, shift float64, solid *color.RGBA) {
+		// Tail first so the head ends up on top
+		for i := len(g.snake) - 1; i >= 0; i-- {
+			c, radius := g.segmentStyle(i)
+			if solid != nil {
+				c = *solid
+			}
+			r := radius * cell
+			p := positions[i]
+
+			if i > 0 {
+				// Joint towards the next segment, following it across wrapped edges
+				next := positions[i-1]
+				d := Vector2{wrapDelta(next.X-p.X, g.gridW), wrapDelta(next.Y-p.Y, g.gridH)}
+				_, nextRadius := g.segmentStyle(i - 1)
+				width := math.Min(radius, nextRadius) * 2 * cell
+				drawCopies(p, func(x, y float64) {
+					batch.line(dst, x+shift, y+shift, x+shift+d.X*cell, y+shift+d.Y*cell, width, c)
+				})
+			}
+			drawCopies(p, func(x, y float64) {
+				batch.circle(dst, x+shift, y+shift, r, c)
+			})
+		}
+		batch.flush(dst)
+	}
+
+	// Soft shadow, composited at low alpha so overlapping joints stay even
+	shadow := color.RGBA{palette.Shadow.R, palette.Shadow.G, palette.Shadow.B, 255}
+	drawTube(layer, 2*g.scaleFactor, &shadow)
+	op := &ebiten.DrawImageOptions{}
+	op.ColorScale.ScaleAlpha(float32(palette.Shadow.A) / 255 * 0.3)
+	screen.DrawImage(g.snakeLayer, op)
+
+	g.snakeLayer.Clear()
+	drawTube(layer, 0, nil)
+	screen.DrawImage(g.snakeLayer, nil)
+
+	// Head highlight
+	headColor, headRadius := g.segmentStyle(0)
+	highlight := color.RGBA{
+		uint8(math.Min(255, float64(headColor.R)+80)),
+		uint8(math.Min(255, float64(headColor.G)+80)),
+		uint8(math.Min(255, float64(headColor.B)+80)),
+		150,
+	}
+	hx, hy := toScreen(positions[0])
+	if image.Pt(int(hx), int(hy)).In(arena) {
+		offset := headRadius * cell * 0.35
+		batch.circle(screen, hx-offset, hy-offset, headRadius*cell*0.3, highlight)
+		batch.flush(screen)
+	}
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"io/fs"
+	"log"
+	"path"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+const (
+	themesDir      = "assets/themes"
+	defaultThemeID = "classic"
+)
+
+// ==================== THEMES ====================
+
+// themeColor is a colour written as "#rrggbb" or "#rrggbbaa" in theme files.
+type themeColor color.RGBA
+
+func (c themeColor) MarshalText() ([]byte, error) {
+	if c.A == 255 {
+		return []byte(fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)), nil
+	}
+	return []byte(fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)), nil
+}
+
+func (c *themeColor) UnmarshalText(b []byte) error {
+	s := strings.TrimPrefix(string(b), "#")
+	var r, g, bl, a uint8 = 0, 0, 0, 255
+	var err error
+	switch len(s) {
+	case 6:
+		_, err = fmt.Sscanf(s, "%02x%02x%02x", &r, &g, &bl)
+	case 8:
+		_, err = fmt.Sscanf(s, "%02x%02x%02x%02x", &r, &g, &bl, &a)
+	default:
+		return fmt.Errorf("colour %q must be #rrggbb or #rrggbbaa", string(b))
+	}
+	if err != nil {
+		return fmt.Errorf("colour %q: %w", string(b), err)
+	}
+	*c = themeColor{r, g, bl, a}
+	return nil
+}
+
+// RGBA lets a themeColor be used wherever a color.Color is expected.
+func (c themeColor) RGBA() (r, g, b, a uint32) {
+	return color.RGBA(c).RGBA()
+}
+
+// Theme is the full colour palette: the arena, the snake and items, the
+// background animation and every menu, overlay and HUD colour.
+type Theme struct {
+	ID   string `json:"-" toml:"-"`
+	Name string `json:"name" toml:"name"`
+
+	Background     themeColor    `json:"background" toml:"background"`
+	Grid           themeColor    `json:"grid" toml:"grid"`
+	BackgroundTint themeColor    `json:"background_tint" toml:"background_tint"` // shimmering arena cells
+	Head           themeColor    `json:"head" toml:"head"`
+	HeadBoost      themeColor    `json:"head_boost" toml:"head_boost"`
+	HeadSlow       themeColor    `json:"head_slow" toml:"head_slow"`
+	HeadFlash      themeColor    `json:"head_flash" toml:"head_flash"`
+	Body           themeColor    `json:"body" toml:"body"`
+	Food           themeColor    `json:"food" toml:"food"`
+	FoodCombo      themeColor    `json:"food_combo" toml:"food_combo"` // alternates with food during a combo
+	PowerUps       [3]themeColor `json:"power_ups" toml:"power_ups"`   // bonus, speed boost, shield
+	Hazard         themeColor    `json:"hazard" toml:"hazard"`
+	Shadow         themeColor    `json:"shadow" toml:"shadow"`
+	Crash          themeColor    `json:"crash" toml:"crash"`
+	Meteors        []themeColor  `json:"meteors" toml:"meteors"`
+	Stars          []themeColor  `json:"stars" toml:"stars"`
+
+	UI UIColors `json:"ui" toml:"ui"`
+}
+
+// UIColors are the text and panel colours shared by every screen.
+type UIColors struct {
+	Title           themeColor `json:"title" toml:"title"`
+	Selected        themeColor `json:"selected" toml:"selected"`
+	Item            themeColor `json:"item" toml:"item"`
+	Text            themeColor `json:"text" toml:"text"`
+	Info            themeColor `json:"info" toml:"info"`
+	Accent          themeColor `json:"accent" toml:"accent"`
+	Heading         themeColor `json:"heading" toml:"heading"`
+	Highlight       themeColor `json:"highlight" toml:"highlight"`
+	Soft            themeColor `json:"soft" toml:"soft"`
+	Danger          themeColor `json:"danger" toml:"danger"`
+	Overlay         themeColor `json:"overlay" toml:"overlay"`
+	GameOverOverlay themeColor `json:"game_over_overlay" toml:"game_over_overlay"`
+	HUDBackground   themeColor `json:"hud_background" toml:"hud_background"`
+	HUDText         themeColor `json:"hud_text" toml:"hud_text"`
+	BarTrack        themeColor `json:"bar_track" toml:"bar_track"`
+	BarSpeed        themeColor `json:"bar_speed" toml:"bar_speed"`
+	BarShield       themeColor `json:"bar_shield" toml:"bar_shield"`
+}
+
+// Green/black/red, the original look
+var classicTheme = Theme{
+	ID:             "classic",
+	Name:           "Classic Green",
+	Background:     themeColor{5, 10, 5, 255},    // Deep black-green
+	Grid:           themeColor{30, 60, 30, 255},  // Subtle dark green grid, alpha is animated
+	BackgroundTint: themeColor{77, 255, 77, 255}, // Green-tinted background cells
+	Head:           themeColor{0, 255, 50, 255},  // Bright lime green
+	HeadBoost:      themeColor{150, 255, 100, 255},
+	HeadSlow:       themeColor{100, 150, 100, 255},
+	HeadFlash:      themeColor{200, 255, 200, 255},
+	Body:           themeColor{0, 180, 30, 255},  // Forest green
+	Food:           themeColor{255, 50, 50, 255}, // Bright red (highly visible)
+	FoodCombo:      themeColor{255, 255, 50, 255},
+	PowerUps:       [3]themeColor{{255, 255, 100, 255}, {120, 255, 120, 255}, {120, 120, 255, 255}},
+	Hazard:         themeColor{140, 90, 60, 255},
+	Shadow:         themeColor{0, 0, 0, 120},
+	Crash:          themeColor{255, 100, 100, 255},
+	Meteors: []themeColor{
+		{255, 100, 50, 255},  // Red-orange
+		{255, 150, 100, 255}, // Orange
+		{255, 200, 150, 255}, // Light orange
+		{200, 50, 50, 255},   // Dark red
+	},
+	Stars: []themeColor{
+		{200, 255, 200, 255}, // Light green
+		{150, 255, 150, 255}, // Medium green
+		{100, 200, 100, 255}, // Forest green
+		{255, 255, 255, 255}, // White for variety
+	},
+	UI: UIColors{
+		Title:           themeColor{100, 255, 100, 255},
+		Selected:        themeColor{0, 255, 100, 255},
+		Item:            themeColor{150, 255, 150, 255},
+		Text:            themeColor{200, 255, 200, 255},
+		Info:            themeColor{200, 200, 255, 255},
+		Accent:          themeColor{255, 200, 100, 255},
+		Heading:         themeColor{255, 150, 150, 255},
+		Highlight:       themeColor{255, 255, 100, 255},
+		Soft:            themeColor{255, 255, 200, 255},
+		Danger:          themeColor{255, 100, 100, 255},
+		Overlay:         themeColor{0, 0, 0, 185},
+		GameOverOverlay: themeColor{50, 0, 0, 150},
+		HUDBackground:   themeColor{0, 20, 0, 150},
+		HUDText:         themeColor{100, 255, 100, 255},
+		BarTrack:        themeColor{20, 20, 20, 180},
+		BarSpeed:        themeColor{100, 255, 100, 255},
+		BarShield:       themeColor{100, 150, 255, 255},
+	},
+}
+
+// Pure black and white with saturated, well separated item colours
+var highContrastTheme = Theme{
+	ID:             "high-contrast",
+	Name:           "High Contrast",
+	Background:     themeColor{0, 0, 0, 255},
+	Grid:           themeColor{110, 110, 110, 255},
+	BackgroundTint: themeColor{0, 0, 0, 255},
+	Head:           themeColor{255, 255, 255, 255},
+	HeadBoost:      themeColor{255, 255, 160, 255},
+	HeadSlow:       themeColor{170, 170, 170, 255},
+	HeadFlash:      themeColor{0, 255, 255, 255},
+	Body:           themeColor{255, 230, 0, 255},
+	Food:           themeColor{255, 0, 255, 255},
+	FoodCombo:      themeColor{255, 255, 255, 255},
+	PowerUps:       [3]themeColor{{255, 140, 0, 255}, {0, 255, 0, 255}, {0, 200, 255, 255}},
+	Hazard:         themeColor{150, 150, 150, 255},
+	Shadow:         themeColor{0, 0, 0, 0},
+	Crash:          themeColor{255, 255, 255, 255},
+	Meteors:        []themeColor{{255, 255, 255, 255}, {200, 200, 200, 255}},
+	Stars:          []themeColor{{255, 255, 255, 255}},
+	UI: UIColors{
+		Title:           themeColor{255, 255, 255, 255},
+		Selected:        themeColor{255, 230, 0, 255},
+		Item:            themeColor{255, 255, 255, 255},
+		Text:            themeColor{255, 255, 255, 255},
+		Info:            themeColor{0, 230, 255, 255},
+		Accent:          themeColor{255, 230, 0, 255},
+		Heading:         themeColor{0, 230, 255, 255},
+		Highlight:       themeColor{255, 230, 0, 255},
+		Soft:            themeColor{255, 255, 255, 255},
+		Danger:          themeColor{255, 80, 255, 255},
+		Overlay:         themeColor{0, 0, 0, 230},
+		GameOverOverlay: themeColor{0, 0, 0, 220},
+		HUDBackground:   themeColor{0, 0, 0, 230},
+		HUDText:         themeColor{255, 255, 255, 255},
+		BarTrack:        themeColor{80, 80, 80, 255},
+		BarSpeed:        themeColor{0, 255, 0, 255},
+		BarShield:       themeColor{0, 200, 255, 255},
+	},
+}
+
+// Okabe-Ito palette, readable with the common forms of colour blindness
+var colourBlindTheme = Theme{
+	ID:             "colour-blind",
+	Name:           "Colour-blind Safe",
+	Background:     themeColor{12, 14, 24, 255},
+	Grid:           themeColor{50, 60, 90, 255},
+	BackgroundTint: themeColor{86, 180, 233, 255},
+	Head:           themeColor{86, 180, 233, 255}, // Sky blue
+	HeadBoost:      themeColor{160, 215, 245, 255},
+	HeadSlow:       themeColor{60, 120, 160, 255},
+	HeadFlash:      themeColor{255, 255, 255, 255},
+	Body:           themeColor{0, 114, 178, 255},  // Blue
+	Food:           themeColor{230, 159, 0, 255},  // Orange
+	FoodCombo:      themeColor{240, 228, 66, 255}, // Yellow
+	PowerUps:       [3]themeColor{{240, 228, 66, 255}, {0, 158, 115, 255}, {204, 121, 167, 255}},
+	Hazard:         themeColor{140, 140, 140, 255},
+	Shadow:         themeColor{0, 0, 0, 120},
+	Crash:          themeColor{213, 94, 0, 255}, // Vermillion
+	Meteors:        []themeColor{{230, 159, 0, 255}, {213, 94, 0, 255}, {240, 228, 66, 255}},
+	Stars:          []themeColor{{255, 255, 255, 255}, {86, 180, 233, 255}},
+	UI: UIColors{
+		Title:           themeColor{86, 180, 233, 255},
+		Selected:        themeColor{240, 228, 66, 255},
+		Item:            themeColor{220, 220, 230, 255},
+		Text:            themeColor{235, 235, 240, 255},
+		Info:            themeColor{86, 180, 233, 255},
+		Accent:          themeColor{230, 159, 0, 255},
+		Heading:         themeColor{204, 121, 167, 255},
+		Highlight:       themeColor{240, 228, 66, 255},
+		Soft:            themeColor{255, 255, 255, 255},
+		Danger:          themeColor{213, 94, 0, 255},
+		Overlay:         themeColor{0, 0, 0, 190},
+		GameOverOverlay: themeColor{20, 10, 0, 170},
+		HUDBackground:   themeColor{0, 0, 20, 170},
+		HUDText:         themeColor{235, 235, 240, 255},
+		BarTrack:        themeColor{40, 40, 50, 200},
+		BarSpeed:        themeColor{0, 158, 115, 255},
+		BarShield:       themeColor{204, 121, 167, 255},
+	},
+}
+
+// palette is the theme everything is currently drawn with.
+var palette = classicTheme
+
+// loadThemes returns the built-in themes followed by every .json or .toml
+// theme in dir, bundled ones first and then the player's own. Theme files
+// only need the colours they change; the rest come from the classic theme.
+func loadThemes(dir string) []Theme {
+	themes := []Theme{classicTheme, highContrastTheme, colourBlindTheme}
+	seen := map[string]bool{}
+	for _, t := range themes {
+		seen[t.ID] = true
+	}
+
+	for _, fsys := range assetSources() {
+		entries, err := fs.ReadDir(fsys, dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			ext := path.Ext(e.Name())
+			id := strings.TrimSuffix(e.Name(), ext)
+			if e.IsDir() || (ext != ".json" && ext != ".toml") || seen[id] {
+				continue
+			}
+			t, err := loadTheme(fsys, path.Join(dir, e.Name()))
+			if err != nil {
+				log.Printf("theme %s: %v", e.Name(), err)
+				continue
+			}
+			t.ID = id
+			seen[id] = true
+			themes = append(themes, t)
+		}
+	}
+	return themes
+}
+
+func loadTheme(fsys fs.FS, file string) (Theme, error) {
+	t := classicTheme
+	// Lists replace rather than merge, so start them empty
+	t.Meteors, t.Stars = nil, nil
+
+	data, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return t, err
+	}
+	if path.Ext(file) == ".toml" {
+		err = toml.Unmarshal(data, &t)
+	} else {
+		err = json.Unmarshal(data, &t)
+	}
+	if err != nil {
+		return t, err
+	}
+
+	if t.Name == "" {
+		t.Name = strings.TrimSuffix(path.Base(file), path.Ext(file))
+	}
+	if len(t.Meteors) == 0 {
+		t.Meteors = classicTheme.Meteors
+	}
+	if len(t.Stars) == 0 {
+		t.Stars = classicTheme.Stars
+	}
+	return t, nil
+}
+
+// applyTheme switches the palette to the active profile's theme, or the
+// classic one if it is missing. Headless games load no themes at all.
+func (g *Game) applyTheme() {
+	palette = classicTheme
+	for _, t := range g.themes {
+		if t.ID == g.profile.Theme {
+			palette = t
+		}
+	}
+}
+
+// cycleTheme steps through the available themes and applies it immediately.
+// With none loaded, as in a headless game, there is nothing to step through.
+func (g *Game) cycleTheme(delta int) {
+	if len(g.themes) == 0 {
+		return
+	}
+	current := 0
+	for i, t := range g.themes {
+		if t.ID == palette.ID {
+			current = i
+		}
+	}
+	g.profile.Theme = g.themes[(current+delta+len(g.themes))%len(g.themes)].ID
+	g.applyTheme()
+	g.saveGameData()
+}
+
+// rgba converts a theme colour for code that works on the components.
+func (c themeColor) rgba() color.RGBA {
+	return color.RGBA(c)
+}
@@ -0,0 +1,633 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"golang.org/x/image/font"
+)
+
+// ==================== UI TOOLKIT ====================
+
+// Screens describe themselves every frame as a tree of widgets built from
+// the current game state. The tree is walked twice per frame: by Update, to
+// feed keyboard, controller and pointer input to the widgets, and by Draw,
+// to render them. Both walks run the same layout, so what is drawn is always
+// what can be clicked.
+
+// Rect is a box in UI coordinates.
+type Rect struct{ X, Y, W, H float64 }
+
+func (r Rect) contains(p Vector2) bool {
+	return p.X >= r.X && p.X < r.X+r.W && p.Y >= r.Y && p.Y < r.Y+r.H
+}
+
+func (r Rect) inset(d float64) Rect {
+	return Rect{r.X + d, r.Y + d, r.W - 2*d, r.H - 2*d}
+}
+
+// Anchor says where a widget sits in the space it is given.
+type Anchor int
+
+const (
+	AnchorCenter Anchor = iota
+	AnchorTop
+	AnchorBottom
+	AnchorLeft
+	AnchorRight
+	AnchorTopLeft
+	AnchorTopRight
+	AnchorBottomLeft
+	AnchorBottomRight
+)
+
+// place positions a w by h box inside r.
+func (a Anchor) place(r Rect, w, h float64) Rect {
+	x := r.X + (r.W-w)/2
+	y := r.Y + (r.H-h)/2
+	switch a {
+	case AnchorLeft, AnchorTopLeft, AnchorBottomLeft:
+		x = r.X
+	case AnchorRight, AnchorTopRight, AnchorBottomRight:
+		x = r.X + r.W - w
+	}
+	switch a {
+	case AnchorTop, AnchorTopLeft, AnchorTopRight:
+		y = r.Y
+	case AnchorBottom, AnchorBottomLeft, AnchorBottomRight:
+		y = r.Y + r.H - h
+	}
+	return Rect{x, y, w, h}
+}
+
+// Widget is anything the toolkit can lay out.
+type Widget interface {
+	Size(ui *UI) (w, h float64)
+	// Render draws the widget during the draw pass and reacts to input
+	// during the input pass.
+	Render(ui *UI, r Rect)
+}
+
+// activatable widgets respond when their List row is chosen. delta is 0 for
+// Confirm or a click, and -1 or +1 for Left/Right or a tap on either half.
+type activatable interface {
+	Activate(delta int)
+}
+
+// UI is the state of one walk over a widget tree.
+type UI struct {
+	g     *Game
+	dst   *ebiten.Image // nil during the input pass
+	face  font.Face
+	scale float64 // UI pixels per design pixel
+
+	hover   Vector2 // mouse position, when it moved this tick
+	hovered bool
+	tap     Vector2 // unconsumed tap position
+	tapped  bool
+
+	inList  bool // rendering a List row
+	focused bool // rendering the selected List row
+}
+
+// Widgets are laid out in design pixels, the sizes they have in a
+// reference-sized window at 100% scale. A UI walk multiplies them by its
+// scale and draws text with a face rasterised at the matching size, so the
+// UI grows with the window without blurring.
+
+// uiScale is the scale menus and overlays are drawn at.
+func (g *Game) uiScale() float64 {
+	return g.viewScale() * scalePercent(g.access().UIScale)
+}
+
+func (g *Game) newUI(dst *ebiten.Image, scale float64) *UI {
+	return &UI{g: g, dst: dst, face: fontFace(uiFontSize * scale), scale: scale}
+}
+
+// updateUI runs the input pass over a widget tree.
+func (g *Game) updateUI(root Widget) {
+	ui := g.newUI(nil, g.uiScale())
+	if g.pointer.moved {
+		ui.hover, ui.hovered = g.pointer.hover, true
+	}
+	if g.pointer.tapped {
+		ui.tap, ui.tapped = g.pointer.tap, true
+	}
+	root.Render(ui, Rect{0, 0, float64(g.screenWidth), float64(g.screenHeight)})
+}
+
+// renderUI runs the draw pass over a widget tree.
+func (g *Game) renderUI(screen *ebiten.Image, root Widget) {
+	g.renderUIAt(screen, root, g.uiScale())
+}
+
+// renderUIAt draws a widget tree at a given scale, e.g. the HUD's.
+func (g *Game) renderUIAt(screen *ebiten.Image, root Widget, scale float64) {
+	root.Render(g.newUI(screen, scale), Rect{0, 0, float64(g.screenWidth), float64(g.screenHeight)})
+}
+
+func (ui *UI) drawing() bool {
+	return ui.dst != nil
+}
+
+// px converts design pixels to UI pixels.
+func (ui *UI) px(v float64) float64 {
+	return v * ui.scale
+}
+
+func (ui *UI) lineHeight() float64 {
+	return float64(ui.face.Metrics().Height.Ceil())
+}
+
+func (ui *UI) textWidth(s string) float64 {
+	return measureText(ui.face, s)
+}
+
+// text draws s with its top-left corner at x, y.
+func (ui *UI) text(s string, x, y float64, c color.Color) {
+	drawText(ui.dst, ui.face, s, x, y, c)
+}
+
+func (ui *UI) fill(r Rect, c color.Color) {
+	vector.DrawFilledRect(ui.dst, float32(r.X), float32(r.Y), float32(r.W), float32(r.H), c, false)
+}
+
+// takeTap consumes a tap inside r and reports which half of r it hit.
+func (ui *UI) takeTap(r Rect) (delta int, ok bool) {
+	if !ui.tapped || !r.contains(ui.tap) {
+		return 0, false
+	}
+	ui.tapped = false
+	ui.g.tapped()
+	if ui.tap.X < r.X+r.W/2 {
+		return -1, true
+	}
+	return 1, true
+}
+
+// itemColor is the colour for text without its own colour: the selected
+// colour in a focused row, the item colour in other rows and the body text
+// colour elsewhere.
+func (ui *UI) itemColor(c color.Color) color.Color {
+	switch {
+	case c != nil:
+		return c
+	case ui.focused:
+		return palette.UI.Selected
+	case ui.inList:
+		return palette.UI.Item
+	}
+	return palette.UI.Text
+}
+
+// ==================== LAYOUT WIDGETS ====================
+
+// Screen covers the whole UI with an optional backdrop and gives every child
+// the full area, so panels can anchor to different edges.
+type Screen struct {
+	Backdrop color.Color
+	Children []Widget
+}
+
+func (s *Screen) Size(ui *UI) (float64, float64) {
+	return float64(ui.g.screenWidth), float64(ui.g.screenHeight)
+}
+
+func (s *Screen) Render(ui *UI, r Rect) {
+	if ui.drawing() && s.Backdrop != nil {
+		ui.fill(r, s.Backdrop)
+	}
+	for _, c := range s.Children {
+		c.Render(ui, r)
+	}
+}
+
+// Panel stacks its children top to bottom and anchors the stack in the
+// space it is given. Children get the panel's full inner width and align
+// themselves within it.
+type Panel struct {
+	Anchor     Anchor
+	Children   []Widget
+	Spacing    float64 // extra space between children
+	Padding    float64
+	Margin     float64 // kept clear between the panel and the edges
+	MinWidth   float64
+	Background color.Color
+}
+
+func (p *Panel) Size(ui *UI) (float64, float64) {
+	w, h := ui.px(p.MinWidth), 0.0
+	for i, c := range p.Children {
+		cw, ch := c.Size(ui)
+		if cw > w {
+			w = cw
+		}
+		h += ch
+		if i > 0 {
+			h += ui.px(p.Spacing)
+		}
+	}
+	edge := 2 * ui.px(p.Padding+p.Margin)
+	return w + edge, h + edge
+}
+
+func (p *Panel) Render(ui *UI, r Rect) {
+	w, h := p.Size(ui)
+	margin := ui.px(p.Margin)
+	box := p.Anchor.place(r.inset(margin), w-2*margin, h-2*margin)
+	if ui.drawing() && p.Background != nil {
+		ui.fill(box, p.Background)
+	}
+
+	inner := box.inset(ui.px(p.Padding))
+	y := inner.Y
+	for _, c := range p.Children {
+		_, ch := c.Size(ui)
+		c.Render(ui, Rect{inner.X, y, inner.W, ch})
+		y += ch + ui.px(p.Spacing)
+	}
+}
+
+// Row lays its cells out left to right in columns. A zero width sizes the
+// column to its cell.
+type Row struct {
+	Cells   []Widget
+	Widths  []float64
+	Spacing float64
+}
+
+func (row *Row) column(ui *UI, i int) float64 {
+	if i < len(row.Widths) && row.Widths[i] > 0 {
+		return ui.px(row.Widths[i])
+	}
+	w, _ := row.Cells[i].Size(ui)
+	return w
+}
+
+func (row *Row) Size(ui *UI) (float64, float64) {
+	w, h := 0.0, 0.0
+	for i, c := range row.Cells {
+		w += row.column(ui, i)
+		if i > 0 {
+			w += ui.px(row.Spacing)
+		}
+		if _, ch := c.Size(ui); ch > h {
+			h = ch
+		}
+	}
+	return w, h
+}
+
+// Rows centre themselves in the width they are given.
+func (row *Row) Render(ui *UI, r Rect) {
+	w, h := row.Size(ui)
+	box := AnchorCenter.place(r, w, h)
+	x := box.X
+	for i, c := range row.Cells {
+		cw := row.column(ui, i)
+		c.Render(ui, Rect{x, box.Y, cw, h})
+		x += cw + ui.px(row.Spacing)
+	}
+}
+
+// Gap is empty vertical space.
+type Gap float64
+
+func (g Gap) Size(ui *UI) (float64, float64) { return 0, ui.px(float64(g)) }
+func (g Gap) Render(ui *UI, r Rect)          {}
+
+// ==================== CONTROL WIDGETS ====================
+
+// Label is a line of text. A label with OnTap reacts to taps and clicks.
+type Label struct {
+	Text  string
+	Color color.Color // nil for the default, see itemColor
+	Align Anchor      // AnchorCenter, AnchorLeft or AnchorRight
+	OnTap func(delta int)
+}
+
+// label is shorthand for a centred label in a fixed colour.
+func label(s string, c color.Color) *Label {
+	return &Label{Text: s, Color: c}
+}
+
+func (l *Label) Size(ui *UI) (float64, float64) {
+	return ui.textWidth(l.Text), ui.lineHeight()
+}
+
+func (l *Label) Render(ui *UI, r Rect) {
+	w, h := l.Size(ui)
+	box := l.Align.place(r, w, h)
+	if !ui.drawing() {
+		if l.OnTap != nil {
+			if delta, ok := ui.takeTap(box); ok {
+				l.OnTap(delta)
+			}
+		}
+		return
+	}
+	ui.text(l.Text, box.X, box.Y, ui.itemColor(l.Color))
+}
+
+// Button runs OnClick when chosen. With OnAdjust it also takes Left/Right,
+// and shows arrows while selected to say so.
+type Button struct {
+	Label    string
+	OnClick  func()
+	OnAdjust func(delta int)
+}
+
+func (b *Button) text(focused bool) string {
+	if focused && b.OnAdjust != nil {
+		return "< " + b.Label + " >"
+	}
+	return b.Label
+}
+
+func (b *Button) Size(ui *UI) (float64, float64) {
+	return ui.textWidth(b.text(b.OnAdjust != nil)), ui.lineHeight()
+}
+
+func (b *Button) Render(ui *UI, r Rect) {
+	s := b.text(ui.focused)
+	box := AnchorCenter.place(r, ui.textWidth(s), ui.lineHeight())
+	if !ui.drawing() {
+		if _, ok := ui.takeTap(box); ok {
+			b.Activate(0)
+		}
+		return
+	}
+	ui.text(s, box.X, box.Y, ui.itemColor(nil))
+}
+
+func (b *Button) Activate(delta int) {
+	switch {
+	case delta != 0 && b.OnAdjust != nil:
+		b.OnAdjust(delta)
+	case delta == 0 && b.OnClick != nil:
+		b.OnClick()
+	case delta == 0 && b.OnAdjust != nil:
+		b.OnAdjust(1)
+	}
+}
+
+// Choice shows a named value that Left/Right step through.
+type Choice struct {
+	Label    string
+	Value    string
+	OnChange func(delta int)
+}
+
+func (c *Choice) text(focused bool) string {
+	s := c.Label + ": " + c.Value
+	if focused {
+		return "< " + s + " >"
+	}
+	return s
+}
+
+func (c *Choice) Size(ui *UI) (float64, float64) {
+	return ui.textWidth(c.text(true)), ui.lineHeight()
+}
+
+func (c *Choice) Render(ui *UI, r Rect) {
+	if !ui.drawing() {
+		return
+	}
+	s := c.text(ui.focused)
+	box := AnchorCenter.place(r, ui.textWidth(s), ui.lineHeight())
+	ui.text(s, box.X, box.Y, ui.itemColor(nil))
+}
+
+func (c *Choice) Activate(delta int) {
+	if delta == 0 {
+		delta = 1
+	}
+	c.OnChange(delta)
+}
+
+// Toggle is an on/off switch drawn as a check box.
+type Toggle struct {
+	Label    string
+	On       bool
+	OnChange func()
+}
+
+func (t *Toggle) Size(ui *UI) (float64, float64) {
+	box := ui.lineHeight()
+	return box + ui.px(8) + ui.textWidth(t.Label+": "+onOff(t.On)), box
+}
+
+func (t *Toggle) Render(ui *UI, r Rect) {
+	if !ui.drawing() {
+		return
+	}
+	w, h := t.Size(ui)
+	box := AnchorCenter.place(r, w, h)
+	c := ui.itemColor(nil)
+
+	check := Rect{box.X, box.Y, h, h}.inset(ui.px(1))
+	vector.StrokeRect(ui.dst, float32(check.X), float32(check.Y), float32(check.W), float32(check.H), float32(ui.px(1.5)), c, false)
+	if t.On {
+		ui.fill(check.inset(ui.px(3)), c)
+	}
+	ui.text(t.Label+": "+onOff(t.On), box.X+h+ui.px(8), box.Y, c)
+}
+
+func (t *Toggle) Activate(int) {
+	t.OnChange()
+}
+
+// Slider shows a value between 0 and 1 as a bar, with Text beside it.
+type Slider struct {
+	Label    string
+	Value    float64
+	Text     string
+	OnChange func(delta int)
+}
+
+const sliderWidth = 120.0
+
+func (s *Slider) Size(ui *UI) (float64, float64) {
+	return ui.textWidth(s.Label+": ") + ui.px(sliderWidth) + ui.textWidth(" "+s.Text), ui.lineHeight()
+}
+
+func (s *Slider) Render(ui *UI, r Rect) {
+	if !ui.drawing() {
+		return
+	}
+	w, h := s.Size(ui)
+	box := AnchorCenter.place(r, w, h)
+	c := ui.itemColor(nil)
+
+	ui.text(s.Label+": ", box.X, box.Y, c)
+	x := box.X + ui.textWidth(s.Label+": ")
+	track := Rect{x, box.Y + h/2 - ui.px(3), ui.px(sliderWidth), ui.px(6)}
+	ui.fill(track, palette.UI.BarTrack)
+	track.W *= clamp01(s.Value)
+	ui.fill(track, c)
+	ui.text(" "+s.Text, x+ui.px(sliderWidth), box.Y, c)
+}
+
+func (s *Slider) Activate(delta int) {
+	if delta == 0 {
+		delta = 1
+	}
+	s.OnChange(delta)
+}
+
+// Bar is a progress bar, as used for power-up timers in the HUD.
+type Bar struct {
+	Value float64
+	Width float64
+	Color color.Color
+}
+
+func (b *Bar) Size(ui *UI) (float64, float64) {
+	return ui.px(b.Width), ui.px(6)
+}
+
+func (b *Bar) Render(ui *UI, r Rect) {
+	if !ui.drawing() {
+		return
+	}
+	box := AnchorLeft.place(r, ui.px(b.Width), ui.px(6))
+	ui.fill(box, palette.UI.BarTrack)
+	box.W *= clamp01(b.Value)
+	ui.fill(box, b.Color)
+}
+
+func clamp01(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+
+// ==================== LISTS ====================
+
+// List is a column of selectable rows. Up/Down or hovering moves the cursor;
+// Confirm, Left/Right or a tap activates the selected row. Rows that are
+// not activatable go to OnSelect instead.
+type List struct {
+	Items     []Widget
+	Cursor    *int
+	RowHeight float64 // 0 for the tallest item's height
+	OnSelect  func(i int)
+	OnChange  func() // after any row was activated
+}
+
+const listMarkerGap = 6.0
+
+func (l *List) rowHeight(ui *UI) float64 {
+	if l.RowHeight > 0 {
+		return ui.px(l.RowHeight)
+	}
+	h := 0.0
+	for _, item := range l.Items {
+		if _, ih := item.Size(ui); ih > h {
+			h = ih
+		}
+	}
+	return h
+}
+
+func (l *List) Size(ui *UI) (float64, float64) {
+	w := 0.0
+	for _, item := range l.Items {
+		if iw, _ := item.Size(ui); iw > w {
+			w = iw
+		}
+	}
+	marker := ui.textWidth("►") + ui.px(listMarkerGap)
+	return w + 2*marker, l.rowHeight(ui) * float64(len(l.Items))
+}
+
+func (l *List) activate(i, delta int) {
+	if a, ok := l.Items[i].(activatable); ok {
+		a.Activate(delta)
+	} else if delta == 0 && l.OnSelect != nil {
+		l.OnSelect(i)
+	} else {
+		return
+	}
+	if l.OnChange != nil {
+		l.OnChange()
+	}
+}
+
+func (l *List) Render(ui *UI, r Rect) {
+	n := len(l.Items)
+	if n == 0 {
+		return
+	}
+	if *l.Cursor < 0 || *l.Cursor >= n {
+		*l.Cursor = 0
+	}
+
+	w, h := l.Size(ui)
+	box := AnchorCenter.place(r, w, h)
+	rowH := l.rowHeight(ui)
+	row := func(i int) Rect { return Rect{box.X, box.Y + float64(i)*rowH, box.W, rowH} }
+
+	if !ui.drawing() {
+		l.handleInput(ui, row)
+		return
+	}
+
+	ui.inList = true
+	for i, item := range l.Items {
+		rr := row(i)
+		ui.focused = i == *l.Cursor
+		if ui.focused {
+			iw, _ := item.Size(ui)
+			marker := ui.textWidth("►")
+			y := rr.Y + (rr.H-ui.lineHeight())/2
+			gap := ui.px(listMarkerGap)
+			ui.text("►", rr.X+(rr.W-iw)/2-marker-gap, y, palette.UI.Selected)
+			ui.text("◄", rr.X+(rr.W+iw)/2+gap, y, palette.UI.Selected)
+		}
+		item.Render(ui, rr)
+	}
+	ui.inList, ui.focused = false, false
+}
+
+func (l *List) handleInput(ui *UI, row func(i int) Rect) {
+	g, n := ui.g, len(l.Items)
+	switch {
+	case g.pressed(ActionUp):
+		*l.Cursor = (*l.Cursor - 1 + n) % n
+	case g.pressed(ActionDown):
+		*l.Cursor = (*l.Cursor + 1) % n
+	case g.pressed(ActionLeft):
+		l.activate(*l.Cursor, -1)
+	case g.pressed(ActionRight):
+		l.activate(*l.Cursor, 1)
+	case g.pressed(ActionConfirm):
+		l.activate(*l.Cursor, 0)
+	}
+
+	for i := range l.Items {
+		rr := row(i)
+		if ui.hovered && rr.contains(ui.hover) {
+			*l.Cursor = i
+		}
+		if delta, ok := ui.takeTap(rr); ok {
+			*l.Cursor = i
+			// Plain buttons and rows take a tap anywhere as a click; value
+			// rows step in the direction of the half that was tapped
+			if b, ok := l.Items[i].(*Button); ok && b.OnAdjust == nil {
+				delta = 0
+			} else if _, ok := l.Items[i].(activatable); !ok {
+				delta = 0
+			}
+			l.activate(i, delta)
+			return
+		}
+	}
+}